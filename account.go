@@ -54,12 +54,31 @@ func (a *AccountServiceOp) Retrieve(ctx context.Context) (*Account, error) {
 	return &account, nil
 }
 
+// BalanceFundingSource returns the account's balance funding source
+//
+// ErrNoBalanceFundingSource is returned when the account has none.
+func (a *Account) BalanceFundingSource(ctx context.Context) (*FundingSource, error) {
+	sources, err := a.ListFundingSources(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return sources.Balance()
+}
+
 // CreateFundingSource creates a funding source for the account
 //
+// Bank account details are validated before the request is sent, and checked
+// against Client.ACHDirectory when one is loaded.
+//
 // see: https://docsv2.dwolla.com/#create-a-funding-source-for-an-account
 func (a *Account) CreateFundingSource(ctx context.Context, body *FundingSourceRequest) (*FundingSource, error) {
 	var source FundingSource
 
+	if err := a.client.validateFundingSource(body); err != nil {
+		return nil, err
+	}
+
 	if err := a.client.Post(ctx, "funding-sources", body, nil, &source); err != nil {
 		return nil, err
 	}
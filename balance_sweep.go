@@ -0,0 +1,225 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BalanceSweep moves the part of a balance above a threshold to a bank
+// funding source
+//
+// A sweep sends at most one transfer per balance and destination per day:
+// the transfer's idempotency key is derived from the balance funding source,
+// the destination and Date, so running the sweep again on the same day
+// returns the original transfer instead of creating another.
+type BalanceSweep struct {
+	// Destination is the bank funding source that receives the excess
+	Destination *FundingSource
+	// Threshold is the amount left in the balance
+	Threshold Amount
+	// MetaData is attached to the sweep transfer
+	MetaData MetaData
+	// Date is the day the sweep is for, the current UTC day when zero
+	Date time.Time
+}
+
+// BalanceSweepResult reports the outcome of a balance sweep
+//
+// Amount is zero and Transfer is nil when the balance did not exceed the
+// threshold. When a transfer is returned Amount is the transfer's amount,
+// which differs from the excess if the sweep already ran that day.
+type BalanceSweepResult struct {
+	Source         *FundingSource
+	Destination    *FundingSource
+	Balance        Amount
+	Threshold      Amount
+	Amount         Amount
+	IdempotencyKey string
+	Transfer       *Transfer
+}
+
+// Moved returns true if the sweep transferred funds
+func (r *BalanceSweepResult) Moved() bool {
+	return r.Transfer != nil
+}
+
+// Validate checks the destination and threshold
+func (s *BalanceSweep) Validate() error {
+	var errs validationErrors
+
+	switch {
+	case s.Destination == nil:
+		errs.add("Required", "/destination", "Destination required.")
+	case s.Destination.Type != FundingSourceTypeBank:
+		errs.add("Invalid", "/destination", "Destination must be a bank funding source.")
+	case s.Destination.Removed:
+		errs.add("Invalid", "/destination", "Destination has been removed.")
+	}
+
+	if s.Destination != nil {
+		if _, ok := s.Destination.Links["self"]; !ok {
+			errs.add("Invalid", "/destination", "Destination has no self resource link.")
+		}
+	}
+
+	if s.Threshold.Value != "" {
+		if _, ok := parseCents(s.Threshold.Value); !ok {
+			errs.add("InvalidFormat", "/threshold/value", "Threshold must be a dollar amount such as 100.00.")
+		}
+	}
+
+	if s.Threshold.Currency != "" && !strings.EqualFold(string(s.Threshold.Currency), string(USD)) {
+		errs.add("Invalid", "/threshold/currency", "Currency must be USD.")
+	}
+
+	return errs.err()
+}
+
+// Run sweeps the balance funding source
+//
+// The sweep is validated before the balance is retrieved. A negative
+// balance has nothing to sweep.
+//
+// see: https://docsv2.dwolla.com/#initiate-a-transfer
+func (s *BalanceSweep) Run(ctx context.Context, source *FundingSource) (*BalanceSweepResult, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, source)
+}
+
+// run sweeps the balance funding source without validating the sweep
+func (s *BalanceSweep) run(ctx context.Context, source *FundingSource) (*BalanceSweepResult, error) {
+	if source.Type != FundingSourceTypeBalance {
+		return nil, ErrNoBalanceFundingSource
+	}
+
+	if source.Removed {
+		return nil, errors.New("Balance funding source has been removed")
+	}
+
+	if _, ok := source.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	balance, err := source.RetrieveBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available, ok := parseCents(strings.TrimPrefix(balance.Balance.Value, "-"))
+	if !ok {
+		return nil, fmt.Errorf("Invalid balance %q", balance.Balance.Value)
+	}
+
+	if strings.HasPrefix(balance.Balance.Value, "-") {
+		available = 0
+	}
+
+	threshold, _ := parseCents(s.Threshold.Value)
+	currency := balance.Balance.Currency
+
+	if currency == "" {
+		currency = USD
+	}
+
+	if s.Threshold.Currency != "" && !strings.EqualFold(string(currency), string(s.Threshold.Currency)) {
+		return nil, fmt.Errorf("Balance currency %s does not match threshold currency %s", currency, s.Threshold.Currency)
+	}
+
+	result := &BalanceSweepResult{
+		Source:         source,
+		Destination:    s.Destination,
+		Balance:        balance.Balance,
+		Threshold:      Amount{Value: formatCents(threshold), Currency: currency},
+		Amount:         Amount{Value: formatCents(0), Currency: currency},
+		IdempotencyKey: s.idempotencyKey(source),
+	}
+
+	if available <= threshold {
+		return result, nil
+	}
+
+	result.Amount.Value = formatCents(available - threshold)
+
+	transfer, err := source.client.Transfer.Create(ctx, &TransferRequest{
+		Resource: Resource{Links: Links{
+			"source":      Link{Href: source.Links["self"].Href},
+			"destination": Link{Href: s.Destination.Links["self"].Href},
+		}},
+		Amount:         result.Amount,
+		MetaData:       s.MetaData,
+		IdempotencyKey: result.IdempotencyKey,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer = transfer
+	result.Amount = transfer.Amount
+
+	return result, nil
+}
+
+// idempotencyKey returns the sweep's key for the balance funding source,
+// destination and day
+func (s *BalanceSweep) idempotencyKey(source *FundingSource) string {
+	date := s.Date
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	return fmt.Sprintf("balance-sweep-%s-%s-%s", fundingSourceID(source), fundingSourceID(s.Destination), date.UTC().Format("2006-01-02"))
+}
+
+// fundingSourceID returns the funding source's id, taken from its self link
+// when it has none
+func fundingSourceID(source *FundingSource) string {
+	if source.ID != "" {
+		return source.ID
+	}
+
+	href := source.Links["self"].Href
+
+	return href[strings.LastIndex(href, "/")+1:]
+}
+
+// SweepBalance moves the customer's balance above the sweep's threshold to
+// its destination
+func (c *Customer) SweepBalance(ctx context.Context, sweep *BalanceSweep) (*BalanceSweepResult, error) {
+	if err := sweep.Validate(); err != nil {
+		return nil, err
+	}
+
+	source, err := c.BalanceFundingSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sweep.run(ctx, source)
+}
+
+// SweepBalance moves the account's balance above the sweep's threshold to
+// its destination
+func (a *Account) SweepBalance(ctx context.Context, sweep *BalanceSweep) (*BalanceSweepResult, error) {
+	if err := sweep.Validate(); err != nil {
+		return nil, err
+	}
+
+	source, err := a.BalanceFundingSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sweep.run(ctx, source)
+}
+
+// formatCents formats cents as a dollar amount with two decimal places
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
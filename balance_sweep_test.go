@@ -0,0 +1,217 @@
+package dwolla
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testBalanceSource   = "/funding-sources/b268f6b9-db3b-4ecc-83a2-8823a53ec8b7"
+	testSweepAccountURL = "https://api-sandbox.dwolla.com/accounts/ca32853c-48fa-40be-ae75-77b37504581b"
+)
+
+// testSweepDestination is the verified bank in testdata/funding-sources.json
+var testSweepDestination = &FundingSource{
+	Resource: Resource{Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/funding-sources/04173e17-6398-4d36-a167-9d98c4b1f1c3"}}},
+	ID:       "04173e17-6398-4d36-a167-9d98c4b1f1c3",
+	Type:     FundingSourceTypeBank,
+	Status:   FundingSourceStatusVerified,
+}
+
+func TestFundingSourcesBalance(t *testing.T) {
+	sources := &FundingSources{Embedded: map[string][]FundingSource{"funding-sources": {
+		{ID: "bank", Type: FundingSourceTypeBank},
+		{ID: "removed", Type: FundingSourceTypeBalance, Removed: true},
+		{ID: "balance", Type: FundingSourceTypeBalance},
+	}}}
+
+	source, err := sources.Balance()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "balance", source.ID)
+
+	sources.Embedded["funding-sources"] = sources.Embedded["funding-sources"][:2]
+	source, err = sources.Balance()
+
+	assert.Equal(t, ErrNoBalanceFundingSource, err)
+	assert.Nil(t, source)
+}
+
+func TestCustomerBalanceFundingSource(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/funding-sources": {200, filepath.Join("testdata", "funding-sources.json")},
+		"GET " + testBalanceSource + "/balance":        {200, filepath.Join("testdata", "funding-source-balance.json")},
+	})
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL(testCustomerPath + "/funding-sources")}}}}
+
+	source, err := customer.BalanceFundingSource(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "b268f6b9-db3b-4ecc-83a2-8823a53ec8b7", source.ID)
+
+	balance, err := source.RetrieveBalance(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4616.87", balance.Balance.Value)
+
+	customer.Links = Links{}
+	source, err = customer.BalanceFundingSource(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, source)
+}
+
+func TestAccountBalanceFundingSource(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "funding-sources.json"))
+	account := &Account{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: testSweepAccountURL + "/funding-sources"}}}}
+
+	source, err := account.BalanceFundingSource(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, string(FundingSourceTypeBalance), string(source.Type))
+}
+
+func TestCustomerSweepBalance(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/funding-sources": {200, filepath.Join("testdata", "funding-sources.json")},
+		"GET " + testBalanceSource + "/balance":        {200, filepath.Join("testdata", "funding-source-balance.json")},
+		"POST /transfers":                              {201, filepath.Join("testdata", "transfer.json")},
+	})
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL(testCustomerPath + "/funding-sources")}}}}
+
+	result, err := customer.SweepBalance(ctx, &BalanceSweep{
+		Destination: testSweepDestination,
+		Threshold:   Amount{Value: "1000.5", Currency: USD},
+		MetaData:    MetaData{"reason": "sweep"},
+		Date:        time.Date(2020, 3, 14, 23, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Moved())
+	assert.Equal(t, "4616.87", result.Balance.Value)
+	assert.Equal(t, "1000.50", result.Threshold.Value)
+	assert.Equal(t, "balance-sweep-b268f6b9-db3b-4ecc-83a2-8823a53ec8b7-04173e17-6398-4d36-a167-9d98c4b1f1c3-2020-03-14", result.IdempotencyKey)
+	assert.NotNil(t, result.Transfer)
+	assert.Equal(t, result.Transfer.Amount, result.Amount)
+
+	requests := mc.requestLog()
+	req := requests[len(requests)-1]
+	assert.Equal(t, result.IdempotencyKey, req.Header.Get("Idempotency-Key"))
+
+	reader, err := req.GetBody()
+	assert.Nil(t, err)
+
+	data, _ := ioutil.ReadAll(reader)
+
+	assert.Contains(t, string(data), `"value":"3616.37"`)
+	assert.Contains(t, string(data), `"source":{"href":"https://api-sandbox.dwolla.com`+testBalanceSource+`"}`)
+	assert.Contains(t, string(data), `"destination":{"href":"https://api-sandbox.dwolla.com/funding-sources/04173e17-6398-4d36-a167-9d98c4b1f1c3"}`)
+	assert.Contains(t, string(data), `"reason":"sweep"`)
+}
+
+func TestAccountSweepBalanceBelowThreshold(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /accounts/ca32853c-48fa-40be-ae75-77b37504581b/funding-sources": {200, filepath.Join("testdata", "funding-sources.json")},
+		"GET " + testBalanceSource + "/balance":                              {200, filepath.Join("testdata", "funding-source-balance.json")},
+	})
+	account := &Account{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: testSweepAccountURL + "/funding-sources"}}}}
+
+	result, err := account.SweepBalance(ctx, &BalanceSweep{
+		Destination: testSweepDestination,
+		Threshold:   Amount{Value: "4616.87", Currency: USD},
+	})
+
+	assert.Nil(t, err)
+	assert.False(t, result.Moved())
+	assert.Equal(t, "0.00", result.Amount.Value)
+	assert.Equal(t, "balance-sweep-b268f6b9-db3b-4ecc-83a2-8823a53ec8b7-04173e17-6398-4d36-a167-9d98c4b1f1c3-"+time.Now().UTC().Format("2006-01-02"), result.IdempotencyKey)
+	assert.Equal(t, 0, countMockRequests(mc, "POST", "/transfers"))
+}
+
+func TestBalanceSweepValidate(t *testing.T) {
+	sweep := &BalanceSweep{Threshold: Amount{Value: "10.001", Currency: "eur"}}
+
+	assert.Equal(t, []string{"/destination", "/threshold/value", "/threshold/currency"}, validationErrorPaths(sweep.Validate()))
+
+	balance := &FundingSource{Resource: Resource{Links: Links{"self": Link{Href: "foobar"}}}, Type: FundingSourceTypeBalance}
+	sweep = &BalanceSweep{Destination: balance}
+
+	assert.Equal(t, []string{"/destination"}, validationErrorPaths(sweep.Validate()))
+
+	sweep = &BalanceSweep{Destination: &FundingSource{Type: FundingSourceTypeBank, Removed: true}}
+
+	assert.Equal(t, []string{"/destination", "/destination"}, validationErrorPaths(sweep.Validate()))
+
+	sweep = &BalanceSweep{Destination: testSweepDestination}
+
+	assert.Nil(t, sweep.Validate())
+}
+
+func TestBalanceSweepRunError(t *testing.T) {
+	c, mc := newMockRoutedClient(nil)
+	sweep := &BalanceSweep{Destination: testSweepDestination}
+
+	result, err := sweep.Run(ctx, testSweepDestination)
+
+	assert.Equal(t, ErrNoBalanceFundingSource, err)
+	assert.Nil(t, result)
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL(testCustomerPath + "/funding-sources")}}}}
+	result, err = customer.SweepBalance(ctx, &BalanceSweep{})
+
+	assert.Equal(t, []string{"/destination"}, validationErrorPaths(err))
+	assert.Nil(t, result)
+	assert.Len(t, mc.requestLog(), 0)
+}
+
+func TestBalanceSweepRunNegativeBalance(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testBalanceSource + "/balance": {200, filepath.Join("testdata", "funding-source-balance-negative.json")},
+	})
+
+	source := &FundingSource{Resource: Resource{client: c, Links: Links{
+		"self":    Link{Href: c.BuildAPIURL(testBalanceSource)},
+		"balance": Link{Href: c.BuildAPIURL(testBalanceSource + "/balance")},
+	}}, Type: FundingSourceTypeBalance}
+	result, err := (&BalanceSweep{Destination: testSweepDestination}).Run(ctx, source)
+
+	assert.Nil(t, err)
+	assert.False(t, result.Moved())
+	assert.Equal(t, "0.00", result.Amount.Value)
+	assert.Equal(t, 0, countMockRequests(mc, "POST", "/transfers"))
+}
+
+func TestBalanceSweepRunCurrencyMismatch(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testBalanceSource + "/balance": {200, filepath.Join("testdata", "funding-source-balance-eur.json")},
+	})
+
+	source := &FundingSource{Resource: Resource{client: c, Links: Links{
+		"self":    Link{Href: c.BuildAPIURL(testBalanceSource)},
+		"balance": Link{Href: c.BuildAPIURL(testBalanceSource + "/balance")},
+	}}, Type: FundingSourceTypeBalance}
+	result, err := (&BalanceSweep{Destination: testSweepDestination, Threshold: Amount{Value: "10.00", Currency: USD}}).Run(ctx, source)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 0, countMockRequests(mc, "POST", "/transfers"))
+}
+
+func TestBalanceSweepRunRemovedSource(t *testing.T) {
+	c, mc := newMockRoutedClient(nil)
+
+	source := &FundingSource{Resource: Resource{client: c, Links: Links{
+		"self":    Link{Href: c.BuildAPIURL(testBalanceSource)},
+		"balance": Link{Href: c.BuildAPIURL(testBalanceSource + "/balance")},
+	}}, Type: FundingSourceTypeBalance}
+	source.Removed = true
+	result, err := (&BalanceSweep{Destination: testSweepDestination}).Run(ctx, source)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Len(t, mc.requestLog(), 0)
+}
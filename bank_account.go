@@ -0,0 +1,285 @@
+package dwolla
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// MinAccountNumberLength is the shortest bank account number accepted
+	MinAccountNumberLength = 4
+	// MaxAccountNumberLength is the longest bank account number accepted
+	MaxAccountNumberLength = 17
+)
+
+const (
+	// BankAccountErrorRoutingNumberFormat is when the routing number is not
+	// 9 digits
+	BankAccountErrorRoutingNumberFormat BankAccountErrorCode = "routing-number-format"
+	// BankAccountErrorRoutingNumberPrefix is when the routing number does
+	// not start with a valid Federal Reserve routing symbol
+	BankAccountErrorRoutingNumberPrefix BankAccountErrorCode = "routing-number-prefix"
+	// BankAccountErrorRoutingNumberChecksum is when the routing number's
+	// check digit is wrong
+	BankAccountErrorRoutingNumberChecksum BankAccountErrorCode = "routing-number-checksum"
+	// BankAccountErrorRoutingNumberUnknown is when the routing number is not
+	// in the ACH directory
+	BankAccountErrorRoutingNumberUnknown BankAccountErrorCode = "routing-number-unknown"
+	// BankAccountErrorRoutingNumberInactive is when the routing number has
+	// been replaced by a new routing number
+	BankAccountErrorRoutingNumberInactive BankAccountErrorCode = "routing-number-inactive"
+	// BankAccountErrorAccountNumberFormat is when the account number
+	// contains characters other than digits
+	BankAccountErrorAccountNumberFormat BankAccountErrorCode = "account-number-format"
+	// BankAccountErrorAccountNumberLength is when the account number is not
+	// between 4 and 17 digits
+	BankAccountErrorAccountNumberLength BankAccountErrorCode = "account-number-length"
+)
+
+// BankAccountErrorCode identifies why bank account details were rejected
+type BankAccountErrorCode string
+
+// BankAccountError is returned when bank account details fail offline
+// validation
+//
+// The account number is never included in the error.
+type BankAccountError struct {
+	Code          BankAccountErrorCode
+	RoutingNumber string
+	Message       string
+}
+
+// Error implements the error interface
+func (e *BankAccountError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// ValidateRoutingNumber checks that the routing number is 9 digits with a
+// valid Federal Reserve routing symbol and ABA check digit
+func ValidateRoutingNumber(routingNumber string) error {
+	if len(routingNumber) != 9 || !allDigits(routingNumber) {
+		return &BankAccountError{Code: BankAccountErrorRoutingNumberFormat, RoutingNumber: routingNumber, Message: "Routing number must be 9 digits."}
+	}
+
+	// The first two digits are the Federal Reserve routing symbol: 00 for
+	// the U.S. government, 01-12 for banks, 21-32 for thrifts, 61-72 for
+	// electronic transactions and 80 for traveler's checks.
+	prefix := int(routingNumber[0]-'0')*10 + int(routingNumber[1]-'0')
+
+	if !(prefix <= 12 || (prefix >= 21 && prefix <= 32) || (prefix >= 61 && prefix <= 72) || prefix == 80) {
+		return &BankAccountError{Code: BankAccountErrorRoutingNumberPrefix, RoutingNumber: routingNumber, Message: "Routing number has an invalid prefix."}
+	}
+
+	weights := []int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+
+	for i, weight := range weights {
+		sum += int(routingNumber[i]-'0') * weight
+	}
+
+	if sum%10 != 0 {
+		return &BankAccountError{Code: BankAccountErrorRoutingNumberChecksum, RoutingNumber: routingNumber, Message: "Routing number check digit is invalid."}
+	}
+
+	return nil
+}
+
+// ValidateAccountNumber checks that the account number is between 4 and 17
+// digits
+func ValidateAccountNumber(accountNumber string) error {
+	if len(accountNumber) < MinAccountNumberLength || len(accountNumber) > MaxAccountNumberLength {
+		return &BankAccountError{
+			Code:    BankAccountErrorAccountNumberLength,
+			Message: fmt.Sprintf("Account number must be between %d and %d digits.", MinAccountNumberLength, MaxAccountNumberLength),
+		}
+	}
+
+	if !allDigits(accountNumber) {
+		return &BankAccountError{Code: BankAccountErrorAccountNumberFormat, Message: "Account number must only contain digits."}
+	}
+
+	return nil
+}
+
+// Validate checks the routing and account numbers offline
+//
+// Requests without a routing number, such as exchange or Plaid funding
+// sources and updates, are not checked. A *BankAccountError is returned for
+// the first problem found.
+func (f *FundingSourceRequest) Validate() error {
+	if f.RoutingNumber == "" && f.AccountNumber == "" {
+		return nil
+	}
+
+	if err := ValidateRoutingNumber(f.RoutingNumber); err != nil {
+		return err
+	}
+
+	return ValidateAccountNumber(f.AccountNumber)
+}
+
+// ACHParticipant is a financial institution from the Fed ACH participant
+// directory
+type ACHParticipant struct {
+	RoutingNumber    string
+	OfficeCode       string
+	ServicingFRB     string
+	RecordTypeCode   string
+	ChangeDate       string
+	NewRoutingNumber string
+	Name             string
+	Address          string
+	City             string
+	State            string
+	PostalCode       string
+	Phone            string
+	StatusCode       string
+}
+
+// Active returns true if the routing number has not been replaced by a new
+// routing number
+func (p *ACHParticipant) Active() bool {
+	return p.RecordTypeCode != "2" && strings.Trim(p.NewRoutingNumber, "0") == ""
+}
+
+// ACHDirectory is a locally loaded Fed ACH participant directory
+//
+// Set Client.ACHDirectory to check routing numbers against it before
+// funding sources are created.
+type ACHDirectory struct {
+	participants map[string]*ACHParticipant
+}
+
+// LoadACHDirectory parses a Fed ACH participant directory in the fixed
+// width FedACHdir.txt format
+//
+// see: https://www.frbservices.org/EPaymentsDirectory/achFormat.html
+func LoadACHDirectory(r io.Reader) (*ACHDirectory, error) {
+	directory := &ACHDirectory{participants: map[string]*ACHParticipant{}}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		record := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+
+		if len(record) < 149 {
+			return nil, fmt.Errorf("ACH directory line %d is %d characters, expected at least 149", line, len(record))
+		}
+
+		field := func(start, end int) string {
+			return strings.TrimSpace(record[start-1 : end])
+		}
+
+		participant := &ACHParticipant{
+			RoutingNumber:    field(1, 9),
+			OfficeCode:       field(10, 10),
+			ServicingFRB:     field(11, 19),
+			RecordTypeCode:   field(20, 20),
+			ChangeDate:       field(21, 26),
+			NewRoutingNumber: field(27, 35),
+			Name:             field(36, 71),
+			Address:          field(72, 107),
+			City:             field(108, 127),
+			State:            field(128, 129),
+			PostalCode:       field(130, 134),
+			Phone:            field(139, 148),
+			StatusCode:       field(149, 149),
+		}
+
+		directory.participants[participant.RoutingNumber] = participant
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return directory, nil
+}
+
+// LoadACHDirectoryFile parses the Fed ACH participant directory file at the
+// path
+func LoadACHDirectoryFile(path string) (*ACHDirectory, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return LoadACHDirectory(file)
+}
+
+// Len returns the number of participants in the directory
+func (d *ACHDirectory) Len() int {
+	return len(d.participants)
+}
+
+// Lookup returns the participant with the routing number
+func (d *ACHDirectory) Lookup(routingNumber string) (*ACHParticipant, bool) {
+	participant, ok := d.participants[routingNumber]
+
+	return participant, ok
+}
+
+// Check validates the routing number and confirms it belongs to an active
+// participant
+func (d *ACHDirectory) Check(routingNumber string) (*ACHParticipant, error) {
+	if err := ValidateRoutingNumber(routingNumber); err != nil {
+		return nil, err
+	}
+
+	participant, ok := d.Lookup(routingNumber)
+	if !ok {
+		return nil, &BankAccountError{Code: BankAccountErrorRoutingNumberUnknown, RoutingNumber: routingNumber, Message: "Routing number is not in the ACH directory."}
+	}
+
+	if !participant.Active() {
+		return participant, &BankAccountError{
+			Code:          BankAccountErrorRoutingNumberInactive,
+			RoutingNumber: routingNumber,
+			Message:       fmt.Sprintf("Routing number has been replaced by %s.", participant.NewRoutingNumber),
+		}
+	}
+
+	return participant, nil
+}
+
+// validateFundingSource checks a funding source request before it is sent
+//
+// The routing and account numbers are validated offline unless validation
+// is disabled. When an ACH directory is loaded the routing number must
+// belong to an active participant, and an empty name is filled in with the
+// participant's name.
+func (c *Client) validateFundingSource(body *FundingSourceRequest) error {
+	if body == nil {
+		return errors.New("No funding source request")
+	}
+
+	if err := c.validate(body); err != nil {
+		return err
+	}
+
+	if c.DisableValidation || c.ACHDirectory == nil || body.RoutingNumber == "" {
+		return nil
+	}
+
+	participant, err := c.ACHDirectory.Check(body.RoutingNumber)
+	if err != nil {
+		return err
+	}
+
+	if body.Name == "" {
+		body.Name = participant.Name
+	}
+
+	return nil
+}
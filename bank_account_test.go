@@ -0,0 +1,201 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bankAccountErrorCode(err error) BankAccountErrorCode {
+	if e, ok := err.(*BankAccountError); ok {
+		return e.Code
+	}
+
+	return ""
+}
+
+func TestValidateRoutingNumber(t *testing.T) {
+	for _, routingNumber := range []string{"222222226", "011000015", "021000021", "322271627"} {
+		assert.Nil(t, ValidateRoutingNumber(routingNumber), routingNumber)
+	}
+
+	for routingNumber, code := range map[string]BankAccountErrorCode{
+		"":           BankAccountErrorRoutingNumberFormat,
+		"22222222":   BankAccountErrorRoutingNumberFormat,
+		"1234567890": BankAccountErrorRoutingNumberFormat,
+		"2222-2222":  BankAccountErrorRoutingNumberFormat,
+		"131000003":  BankAccountErrorRoutingNumberPrefix,
+		"501000003":  BankAccountErrorRoutingNumberPrefix,
+		"222222227":  BankAccountErrorRoutingNumberChecksum,
+		"021000012":  BankAccountErrorRoutingNumberChecksum,
+	} {
+		err := ValidateRoutingNumber(routingNumber)
+
+		assert.Error(t, err, routingNumber)
+		assert.Equal(t, string(code), string(bankAccountErrorCode(err)), routingNumber)
+	}
+}
+
+func TestValidateAccountNumber(t *testing.T) {
+	for _, accountNumber := range []string{"1234", "0123456789", "12345678901234567"} {
+		assert.Nil(t, ValidateAccountNumber(accountNumber), accountNumber)
+	}
+
+	for accountNumber, code := range map[string]BankAccountErrorCode{
+		"":                   BankAccountErrorAccountNumberLength,
+		"123":                BankAccountErrorAccountNumberLength,
+		"123456789012345678": BankAccountErrorAccountNumberLength,
+		"1234-5678":          BankAccountErrorAccountNumberFormat,
+		"12345678a":          BankAccountErrorAccountNumberFormat,
+	} {
+		err := ValidateAccountNumber(accountNumber)
+
+		assert.Error(t, err, accountNumber)
+		assert.Equal(t, string(code), string(bankAccountErrorCode(err)), accountNumber)
+		assert.False(t, accountNumber != "" && strings.Contains(err.Error(), accountNumber))
+	}
+}
+
+func TestFundingSourceRequestValidate(t *testing.T) {
+	assert.Nil(t, (&FundingSourceRequest{Name: "Renamed"}).Validate())
+	assert.Nil(t, (&FundingSourceRequest{RoutingNumber: "222222226", AccountNumber: "0123456789"}).Validate())
+
+	err := (&FundingSourceRequest{AccountNumber: "0123456789"}).Validate()
+	assert.Equal(t, string(BankAccountErrorRoutingNumberFormat), string(bankAccountErrorCode(err)))
+
+	err = (&FundingSourceRequest{RoutingNumber: "222222226"}).Validate()
+	assert.Equal(t, string(BankAccountErrorAccountNumberLength), string(bankAccountErrorCode(err)))
+}
+
+func TestLoadACHDirectory(t *testing.T) {
+	directory, err := LoadACHDirectoryFile(filepath.Join("testdata", "ach-directory.txt"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, directory.Len())
+
+	participant, ok := directory.Lookup("021000021")
+
+	assert.True(t, ok)
+	assert.Equal(t, "JPMORGAN CHASE BANK, NA", participant.Name)
+	assert.Equal(t, "TAMPA", participant.City)
+	assert.Equal(t, "FL", participant.State)
+	assert.Equal(t, "33623", participant.PostalCode)
+	assert.Equal(t, "8134323700", participant.Phone)
+	assert.True(t, participant.Active())
+
+	participant, ok = directory.Lookup("011000028")
+
+	assert.True(t, ok)
+	assert.Equal(t, "011000015", participant.NewRoutingNumber)
+	assert.False(t, participant.Active())
+
+	_, ok = directory.Lookup("122000247")
+	assert.False(t, ok)
+}
+
+func TestLoadACHDirectoryError(t *testing.T) {
+	_, err := LoadACHDirectory(strings.NewReader("021000021O021001208\n"))
+	assert.Error(t, err)
+
+	_, err = LoadACHDirectoryFile(filepath.Join("testdata", "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestACHDirectoryCheck(t *testing.T) {
+	directory, err := LoadACHDirectoryFile(filepath.Join("testdata", "ach-directory.txt"))
+	assert.Nil(t, err)
+
+	participant, err := directory.Check("222222226")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "SANDBOX BANK", participant.Name)
+
+	_, err = directory.Check("222222227")
+	assert.Equal(t, string(BankAccountErrorRoutingNumberChecksum), string(bankAccountErrorCode(err)))
+
+	_, err = directory.Check("322271627")
+	assert.Equal(t, string(BankAccountErrorRoutingNumberUnknown), string(bankAccountErrorCode(err)))
+
+	participant, err = directory.Check("011000028")
+	assert.Equal(t, string(BankAccountErrorRoutingNumberInactive), string(bankAccountErrorCode(err)))
+	assert.Contains(t, err.Error(), "011000015")
+	assert.NotNil(t, participant)
+}
+
+func TestCreateFundingSourceBankAccountValidation(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+		"POST /funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL(testCustomerPath + "/funding-sources")}}}}
+	account := &Account{Resource: Resource{client: c}}
+
+	res, err := customer.CreateFundingSource(ctx, &FundingSourceRequest{RoutingNumber: "222222227", AccountNumber: "0123456789", BankAccountType: FundingSourceBankAccountTypeChecking, Name: "Checking"})
+
+	assert.Equal(t, string(BankAccountErrorRoutingNumberChecksum), string(bankAccountErrorCode(err)))
+	assert.Nil(t, res)
+
+	res, err = account.CreateFundingSource(ctx, &FundingSourceRequest{RoutingNumber: "222222226", AccountNumber: "012", BankAccountType: FundingSourceBankAccountTypeChecking, Name: "Checking"})
+
+	assert.Equal(t, string(BankAccountErrorAccountNumberLength), string(bankAccountErrorCode(err)))
+	assert.Nil(t, res)
+
+	res, err = customer.CreateFundingSource(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	res, err = account.CreateFundingSource(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	assert.Len(t, mc.requestLog(), 0)
+
+	c.DisableValidation = true
+
+	res, err = account.CreateFundingSource(ctx, &FundingSourceRequest{RoutingNumber: "222222226", AccountNumber: "012", BankAccountType: FundingSourceBankAccountTypeChecking, Name: "Checking"})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Len(t, mc.requestLog(), 1)
+}
+
+func TestCreateFundingSourceACHDirectory(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	directory, err := LoadACHDirectoryFile(filepath.Join("testdata", "ach-directory.txt"))
+	assert.Nil(t, err)
+
+	c.ACHDirectory = directory
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL(testCustomerPath + "/funding-sources")}}}}
+
+	res, err := customer.CreateFundingSource(ctx, &FundingSourceRequest{RoutingNumber: "011000028", AccountNumber: "0123456789", BankAccountType: FundingSourceBankAccountTypeChecking})
+
+	assert.Equal(t, string(BankAccountErrorRoutingNumberInactive), string(bankAccountErrorCode(err)))
+	assert.Nil(t, res)
+
+	res, err = customer.CreateFundingSource(ctx, &FundingSourceRequest{RoutingNumber: "322271627", AccountNumber: "0123456789", BankAccountType: FundingSourceBankAccountTypeChecking})
+
+	assert.Equal(t, string(BankAccountErrorRoutingNumberUnknown), string(bankAccountErrorCode(err)))
+	assert.Nil(t, res)
+	assert.Len(t, mc.requestLog(), 0)
+
+	body := &FundingSourceRequest{RoutingNumber: "222222226", AccountNumber: "0123456789", BankAccountType: FundingSourceBankAccountTypeChecking}
+	res, err = customer.CreateFundingSource(ctx, body)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "SANDBOX BANK", body.Name)
+	assert.Len(t, mc.requestLog(), 1)
+
+	body = &FundingSourceRequest{RoutingNumber: "222222226", AccountNumber: "0123456789", BankAccountType: FundingSourceBankAccountTypeChecking, Name: "Payroll"}
+	_, err = customer.CreateFundingSource(ctx, body)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Payroll", body.Name)
+}
@@ -116,6 +116,10 @@ func (b *BeneficialOwnerServiceOp) Retrieve(ctx context.Context, id string) (*Be
 func (b *BeneficialOwnerServiceOp) Update(ctx context.Context, id string, body *BeneficialOwnerRequest) (*BeneficialOwner, error) {
 	var owner BeneficialOwner
 
+	if err := b.client.validate(body); err != nil {
+		return nil, err
+	}
+
 	if err := b.client.Post(ctx, fmt.Sprintf("beneficial-owners/%s", id), body, nil, &owner); err != nil {
 		return nil, err
 	}
@@ -186,6 +190,10 @@ func (b *BeneficialOwner) Update(ctx context.Context, body *BeneficialOwnerReque
 		return errors.New("No self resource link")
 	}
 
+	if err := b.client.validate(body); err != nil {
+		return err
+	}
+
 	return b.client.Post(ctx, b.Links["self"].Href, body, nil, b)
 }
 
@@ -0,0 +1,227 @@
+package dwolla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BeneficialOwnerMatchFunc returns true if an existing beneficial owner is
+// the same person as a desired owner
+type BeneficialOwnerMatchFunc func(existing *BeneficialOwner, desired *BeneficialOwnerRequest) bool
+
+// MatchBeneficialOwnerByName matches beneficial owners by first and last
+// name, ignoring case
+func MatchBeneficialOwnerByName(existing *BeneficialOwner, desired *BeneficialOwnerRequest) bool {
+	return strings.EqualFold(existing.FirstName, desired.FirstName) && strings.EqualFold(existing.LastName, desired.LastName)
+}
+
+// BeneficialOwnerChange is a desired beneficial owner paired with the
+// existing owner it matched
+type BeneficialOwnerChange struct {
+	Owner   *BeneficialOwner
+	Request *BeneficialOwnerRequest
+}
+
+// BeneficialOwnerSyncPlan is the set of changes needed to bring a
+// customer's beneficial owners in line with the desired owners
+//
+// Dwolla only accepts updates for owners with incomplete verification, so
+// those owners are always updated to resubmit their details, while changed
+// owners in any other status are replaced: removed and created again.
+// Unchanged owners, such as verified owners whose name and address match,
+// are left as they are.
+type BeneficialOwnerSyncPlan struct {
+	Create    []*BeneficialOwnerRequest
+	Update    []BeneficialOwnerChange
+	Replace   []BeneficialOwnerChange
+	Remove    []*BeneficialOwner
+	Unchanged []*BeneficialOwner
+}
+
+// Empty returns true if the plan makes no changes
+func (p *BeneficialOwnerSyncPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Replace) == 0 && len(p.Remove) == 0
+}
+
+// String returns a line per planned change, for review before the plan is
+// applied
+func (p *BeneficialOwnerSyncPlan) String() string {
+	var lines []string
+
+	for _, owner := range p.Remove {
+		lines = append(lines, fmt.Sprintf("remove %s %s (%s)", owner.FirstName, owner.LastName, owner.VerificationStatus))
+	}
+
+	for _, change := range p.Replace {
+		lines = append(lines, fmt.Sprintf("replace %s %s (%s)", change.Owner.FirstName, change.Owner.LastName, change.Owner.VerificationStatus))
+	}
+
+	for _, change := range p.Update {
+		lines = append(lines, fmt.Sprintf("update %s %s (%s)", change.Owner.FirstName, change.Owner.LastName, change.Owner.VerificationStatus))
+	}
+
+	for _, request := range p.Create {
+		lines = append(lines, fmt.Sprintf("create %s %s", request.FirstName, request.LastName))
+	}
+
+	for _, owner := range p.Unchanged {
+		lines = append(lines, fmt.Sprintf("keep %s %s (%s)", owner.FirstName, owner.LastName, owner.VerificationStatus))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// PlanBeneficialOwnerSync compares the desired beneficial owners with the
+// customer's existing owners without changing anything
+//
+// Each existing owner is matched to at most one desired owner. A nil match
+// function matches by name.
+func (c *Customer) PlanBeneficialOwnerSync(ctx context.Context, desired []BeneficialOwnerRequest, match BeneficialOwnerMatchFunc) (*BeneficialOwnerSyncPlan, error) {
+	if len(desired) > MaxBeneficialOwners {
+		return nil, fmt.Errorf("%d beneficial owners exceeds the limit of %d", len(desired), MaxBeneficialOwners)
+	}
+
+	if match == nil {
+		match = MatchBeneficialOwnerByName
+	}
+
+	owners, err := c.ListBeneficialOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := owners.Embedded["beneficial-owners"]
+	matched := make([]bool, len(existing))
+	plan := &BeneficialOwnerSyncPlan{}
+
+	for i := range desired {
+		request := &desired[i]
+		found := false
+
+		for j := range existing {
+			owner := &existing[j]
+
+			if matched[j] || !match(owner, request) {
+				continue
+			}
+
+			matched[j] = true
+			found = true
+
+			switch {
+			case owner.VerificationStatus == BeneficialOwnerStatusIncomplete:
+				plan.Update = append(plan.Update, BeneficialOwnerChange{Owner: owner, Request: request})
+			case beneficialOwnerChanged(owner, request):
+				plan.Replace = append(plan.Replace, BeneficialOwnerChange{Owner: owner, Request: request})
+			default:
+				plan.Unchanged = append(plan.Unchanged, owner)
+			}
+
+			break
+		}
+
+		if !found {
+			plan.Create = append(plan.Create, request)
+		}
+	}
+
+	for j := range existing {
+		if !matched[j] {
+			plan.Remove = append(plan.Remove, &existing[j])
+		}
+	}
+
+	return plan, nil
+}
+
+// SyncBeneficialOwners creates, updates and removes the customer's
+// beneficial owners so they match the desired owners
+//
+// Every request that will be sent is validated before any change is made.
+// Removals are applied first so the owner limit is not exceeded, then
+// replacements, updates and creates. The applied plan is returned; use
+// PlanBeneficialOwnerSync for a dry run.
+func (c *Customer) SyncBeneficialOwners(ctx context.Context, desired []BeneficialOwnerRequest, match BeneficialOwnerMatchFunc) (*BeneficialOwnerSyncPlan, error) {
+	plan, err := c.PlanBeneficialOwnerSync(ctx, desired, match)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range plan.Replace {
+		if err := c.client.validate(change.Request); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, change := range plan.Update {
+		if err := c.client.validate(change.Request); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, request := range plan.Create {
+		if err := c.client.validate(request); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, owner := range plan.Remove {
+		if err := owner.Remove(ctx); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, change := range plan.Replace {
+		if err := change.Owner.Remove(ctx); err != nil {
+			return plan, err
+		}
+
+		if _, err := c.CreateBeneficialOwner(ctx, change.Request); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, change := range plan.Update {
+		if err := change.Owner.Update(ctx, change.Request); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, request := range plan.Create {
+		if _, err := c.CreateBeneficialOwner(ctx, request); err != nil {
+			return plan, err
+		}
+	}
+
+	return plan, nil
+}
+
+// beneficialOwnerChanged returns true if the desired owner differs from the
+// fields dwolla returns for the existing owner
+//
+// Date of birth, SSN and passport details are never returned, so only the
+// name and address can be compared.
+func beneficialOwnerChanged(owner *BeneficialOwner, request *BeneficialOwnerRequest) bool {
+	if !strings.EqualFold(owner.FirstName, request.FirstName) || !strings.EqualFold(owner.LastName, request.LastName) {
+		return true
+	}
+
+	a, b := owner.Address, request.Address
+
+	for _, field := range [][2]string{
+		{a.Address1, b.Address1},
+		{a.Address2, b.Address2},
+		{a.Address3, b.Address3},
+		{a.City, b.City},
+		{a.StateProvinceRegion, b.StateProvinceRegion},
+		{a.PostalCode, b.PostalCode},
+		{a.Country, b.Country},
+	} {
+		if !strings.EqualFold(strings.TrimSpace(field[0]), strings.TrimSpace(field[1])) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSyncOwner = "/beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98"
+
+func TestCustomerPlanBeneficialOwnerSync(t *testing.T) {
+	customer := newTestCustomer(newMockClient(200, filepath.Join("testdata", "beneficial-owners.json")))
+
+	desired := []BeneficialOwnerRequest{
+		newTestBeneficialOwnerRequest("joe", "OWNER2", "50265"),
+		newTestBeneficialOwnerRequest("document", "owner1", "50266"),
+		newTestBeneficialOwnerRequest("Jane", "Doe", "50309"),
+	}
+
+	plan, err := customer.PlanBeneficialOwnerSync(ctx, desired, nil)
+
+	assert.Nil(t, err)
+	assert.False(t, plan.Empty())
+	assert.Len(t, plan.Unchanged, 1)
+	assert.Len(t, plan.Replace, 1)
+	assert.Len(t, plan.Create, 1)
+	assert.Empty(t, plan.Update)
+	assert.Empty(t, plan.Remove)
+	assert.Equal(t, plan.String(), strings.Join([]string{
+		"replace document owner1 (document)",
+		"create Jane Doe",
+		"keep Joe owner2 (verified)",
+	}, "\n"))
+}
+
+func TestCustomerSyncBeneficialOwners(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":  {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"POST " + testCustomerPath + "/beneficial-owners": {201, filepath.Join("testdata", "beneficial-owner.json")},
+		"DELETE " + testSyncOwner:                         {200, filepath.Join("testdata", "beneficial-owner.json")},
+	})
+	customer := newTestCustomer(c)
+
+	desired := []BeneficialOwnerRequest{
+		newTestBeneficialOwnerRequest("Joe", "owner2", "50265"),
+		newTestBeneficialOwnerRequest("document", "owner1", "50266"),
+		newTestBeneficialOwnerRequest("Jane", "Doe", "50309"),
+	}
+
+	_, err := customer.SyncBeneficialOwners(ctx, desired, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, countMockRequests(mc, "DELETE", testSyncOwner), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-owners"), 2)
+	assert.Equal(t, countMockRequests(mc, "DELETE", "/beneficial-owners/caa81a5f-ec1e-4559-8b32-d90655bfd03c"), 0)
+}
+
+func TestCustomerSyncBeneficialOwnersRemoveAndUpdate(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners": {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"DELETE " + testSyncOwner:                        {200, filepath.Join("testdata", "beneficial-owner.json")},
+	})
+	customer := newTestCustomer(c)
+
+	plan, err := customer.SyncBeneficialOwners(ctx, []BeneficialOwnerRequest{newTestBeneficialOwnerRequest("Joe", "owner2", "50265")}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Remove, 1)
+	assert.Equal(t, countMockRequests(mc, "DELETE", testSyncOwner), 1)
+
+	c, mc = newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners": {200, filepath.Join("testdata", "beneficial-owners-incomplete.json")},
+		"POST " + testSyncOwner:                          {200, filepath.Join("testdata", "beneficial-owner.json")},
+	})
+	customer = newTestCustomer(c)
+
+	match := func(existing *BeneficialOwner, desired *BeneficialOwnerRequest) bool {
+		return existing.LastName == desired.LastName
+	}
+
+	plan, err = customer.SyncBeneficialOwners(ctx, []BeneficialOwnerRequest{
+		newTestBeneficialOwnerRequest("Document", "owner1", "50265"),
+		newTestBeneficialOwnerRequest("Joe", "owner2", "50265"),
+	}, match)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Update, 1)
+	assert.Len(t, plan.Unchanged, 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testSyncOwner), 1)
+	assert.Equal(t, countMockRequests(mc, "DELETE", testSyncOwner), 0)
+}
+
+func TestCustomerSyncBeneficialOwnersError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners": {200, filepath.Join("testdata", "beneficial-owners.json")},
+	})
+	customer := newTestCustomer(c)
+
+	invalid := newTestBeneficialOwnerRequest("Jane", "Doe", "50309")
+	invalid.SSN = "1234"
+
+	plan, err := customer.SyncBeneficialOwners(ctx, []BeneficialOwnerRequest{invalid}, nil)
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Len(t, plan.Remove, 2)
+	assert.Equal(t, countMockRequests(mc, "DELETE", testSyncOwner), 0)
+
+	desired := make([]BeneficialOwnerRequest, MaxBeneficialOwners+1)
+	plan, err = customer.SyncBeneficialOwners(ctx, desired, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, plan)
+
+	plan, err = (&Customer{}).PlanBeneficialOwnerSync(ctx, nil, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, plan)
+}
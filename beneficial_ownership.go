@@ -0,0 +1,129 @@
+package dwolla
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxBeneficialOwners is the most beneficial owners dwolla allows for a
+// business customer
+const MaxBeneficialOwners = 4
+
+// BeneficialOwnershipReport summarizes whether a business customer's
+// beneficial ownership can be certified
+//
+// Incomplete and Document hold the owners whose verification is still
+// incomplete or waiting on a document. Missing explains why certification
+// is not allowed and is empty when CanCertify is true.
+type BeneficialOwnershipReport struct {
+	Status     CertificationStatus
+	Owners     []BeneficialOwner
+	Verified   []BeneficialOwner
+	Incomplete []BeneficialOwner
+	Document   []BeneficialOwner
+	Limit      int
+	CanCertify bool
+	Missing    []string
+}
+
+// Certified returns true if beneficial ownership is already certified
+func (r *BeneficialOwnershipReport) Certified() bool {
+	return r.Status == CertificationStatusCertified
+}
+
+// BeneficialOwnershipNotReadyError is returned when beneficial ownership
+// cannot be certified yet
+type BeneficialOwnershipNotReadyError struct {
+	Report *BeneficialOwnershipReport
+}
+
+// Error implements the error interface
+func (e *BeneficialOwnershipNotReadyError) Error() string {
+	return fmt.Sprintf("Beneficial ownership cannot be certified: %s", strings.Join(e.Report.Missing, "; "))
+}
+
+// BeneficialOwnershipReport lists the customer's beneficial owners and
+// ownership status and reports what is still needed before ownership can
+// be certified
+//
+// Certification requires every owner to be verified, no more than
+// MaxBeneficialOwners owners and a certify beneficial ownership link on the
+// customer.
+func (c *Customer) BeneficialOwnershipReport(ctx context.Context) (*BeneficialOwnershipReport, error) {
+	owners, err := c.ListBeneficialOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ownership, err := c.RetrieveBeneficialOwnership(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BeneficialOwnershipReport{
+		Status: ownership.Status,
+		Owners: owners.Embedded["beneficial-owners"],
+		Limit:  MaxBeneficialOwners,
+	}
+
+	for _, owner := range report.Owners {
+		switch owner.VerificationStatus {
+		case BeneficialOwnerStatusVerified:
+			report.Verified = append(report.Verified, owner)
+		case BeneficialOwnerStatusDocument:
+			report.Document = append(report.Document, owner)
+			report.Missing = append(report.Missing, fmt.Sprintf("%s %s needs a verification document", owner.FirstName, owner.LastName))
+		default:
+			report.Incomplete = append(report.Incomplete, owner)
+			report.Missing = append(report.Missing, fmt.Sprintf("%s %s has incomplete verification", owner.FirstName, owner.LastName))
+		}
+	}
+
+	if len(report.Owners) > report.Limit {
+		report.Missing = append(report.Missing, fmt.Sprintf("%d beneficial owners exceeds the limit of %d", len(report.Owners), report.Limit))
+	}
+
+	if report.Certified() {
+		return report, nil
+	}
+
+	if _, ok := c.Links["certify-beneficial-ownership"]; !ok {
+		report.Missing = append(report.Missing, "customer has no certify beneficial ownership link")
+	}
+
+	report.CanCertify = len(report.Missing) == 0
+
+	return report, nil
+}
+
+// CertifyIfReady certifies beneficial ownership once the report allows it
+//
+// A *BeneficialOwnershipNotReadyError explaining what is missing is
+// returned when it does not. Ownership that is already certified is left
+// as is.
+//
+// see: https://docsv2.dwolla.com/#certify-beneficial-ownership
+func (c *Customer) CertifyIfReady(ctx context.Context) (*BeneficialOwnershipReport, error) {
+	report, err := c.BeneficialOwnershipReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Certified() {
+		return report, nil
+	}
+
+	if !report.CanCertify {
+		return report, &BeneficialOwnershipNotReadyError{Report: report}
+	}
+
+	if err := c.CertifyBeneficialOwnership(ctx); err != nil {
+		return report, err
+	}
+
+	report.Status = CertificationStatusCertified
+	report.CanCertify = false
+
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerBeneficialOwnershipReport(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":    {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership": {200, filepath.Join("testdata", "beneficial-ownership.json")},
+	})
+	customer := newTestCustomer(c)
+
+	report, err := customer.BeneficialOwnershipReport(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, report.Status, CertificationStatusUncertified)
+	assert.Len(t, report.Owners, 2)
+	assert.Len(t, report.Verified, 1)
+	assert.Len(t, report.Document, 1)
+	assert.Empty(t, report.Incomplete)
+	assert.Equal(t, report.Limit, MaxBeneficialOwners)
+	assert.False(t, report.CanCertify)
+	assert.Equal(t, report.Missing, []string{"document owner1 needs a verification document"})
+}
+
+func TestCustomerBeneficialOwnershipReportLimit(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":    {200, filepath.Join("testdata", "beneficial-owners-limit.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership": {200, filepath.Join("testdata", "beneficial-ownership.json")},
+	})
+	customer := newTestCustomer(c)
+
+	delete(customer.Links, "certify-beneficial-ownership")
+
+	report, err := customer.BeneficialOwnershipReport(ctx)
+
+	assert.Nil(t, err)
+	assert.Len(t, report.Verified, 5)
+	assert.False(t, report.CanCertify)
+	assert.Equal(t, report.Missing, []string{
+		"5 beneficial owners exceeds the limit of 4",
+		"customer has no certify beneficial ownership link",
+	})
+}
+
+func TestCustomerBeneficialOwnershipReportError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	report, err := (&Customer{Resource: Resource{client: c}}).BeneficialOwnershipReport(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, report)
+}
+
+func TestCustomerCertifyIfReady(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":     {200, filepath.Join("testdata", "beneficial-owners-verified.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership":  {200, filepath.Join("testdata", "beneficial-ownership.json")},
+		"POST " + testCustomerPath + "/beneficial-ownership": {200, filepath.Join("testdata", "beneficial-ownership-certified.json")},
+	})
+	customer := newTestCustomer(c)
+
+	report, err := customer.CertifyIfReady(ctx)
+
+	assert.Nil(t, err)
+	assert.True(t, report.Certified())
+	assert.Empty(t, report.Missing)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-ownership"), 1)
+}
+
+func TestCustomerCertifyIfReadyNotReady(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":    {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership": {200, filepath.Join("testdata", "beneficial-ownership.json")},
+	})
+	customer := newTestCustomer(c)
+
+	report, err := customer.CertifyIfReady(ctx)
+
+	assert.IsType(t, &BeneficialOwnershipNotReadyError{}, err)
+	assert.Equal(t, err.Error(), "Beneficial ownership cannot be certified: document owner1 needs a verification document")
+	assert.Equal(t, err.(*BeneficialOwnershipNotReadyError).Report, report)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-ownership"), 0)
+}
+
+func TestCustomerCertifyIfReadyCertified(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath + "/beneficial-owners":    {200, filepath.Join("testdata", "beneficial-owners-verified.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership": {200, filepath.Join("testdata", "beneficial-ownership-certified.json")},
+	})
+	customer := newTestCustomer(c)
+
+	report, err := customer.CertifyIfReady(ctx)
+
+	assert.Nil(t, err)
+	assert.True(t, report.Certified())
+	assert.False(t, report.CanCertify)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-ownership"), 0)
+}
@@ -72,6 +72,18 @@ type Client struct {
 	HTTPClient  HTTPClient
 	Token       *Token
 
+	// DisableValidation skips the client side validation of requests before
+	// they are sent
+	DisableValidation bool
+
+	// ACHDirectory, when set, is used to confirm routing numbers belong to
+	// active financial institutions before funding sources are created
+	ACHDirectory *ACHDirectory
+
+	// RequireOnDemandAuthorization rejects customer funding sources created
+	// without an on-demand authorization attached
+	RequireOnDemandAuthorization bool
+
 	root                   *Resource
 	Account                AccountService
 	BeneficialOwner        BeneficialOwnerService
@@ -79,8 +91,11 @@ type Client struct {
 	Customer               CustomerService
 	Document               DocumentService
 	Event                  EventService
+	Exchange               ExchangeService
+	ExchangePartner        ExchangePartnerService
 	FundingSource          FundingSourceService
 	KBA                    KBAService
+	Label                  LabelService
 	MassPayment            MassPaymentService
 	OnDemandAuthorization  OnDemandAuthorizationService
 	Transfer               TransferService
@@ -97,7 +112,8 @@ type ClientTokenRequest struct {
 
 // ClientToken is a general use client token
 type ClientToken struct {
-	Token string `json:"token"`
+	Token  string `json:"token"`
+	issued time.Time
 }
 
 // New initializes a new dwolla client
@@ -124,8 +140,11 @@ func NewWithHTTPClient(key, secret string, environment Environment, httpClient H
 	c.Customer = &CustomerServiceOp{c}
 	c.Document = &DocumentServiceOp{c}
 	c.Event = &EventServiceOp{c}
+	c.Exchange = &ExchangeServiceOp{c}
+	c.ExchangePartner = &ExchangePartnerServiceOp{c}
 	c.FundingSource = &FundingSourceServiceOp{c}
 	c.KBA = &KBAServiceOp{c}
+	c.Label = &LabelServiceOp{c}
 	c.MassPayment = &MassPaymentServiceOp{c}
 	c.OnDemandAuthorization = &OnDemandAuthorizationServiceOp{c}
 	c.Transfer = &TransferServiceOp{c}
@@ -596,7 +615,38 @@ func (c *Client) SandboxSimulations(ctx context.Context) error {
 //
 // see: https://docsv2.dwolla.com/#create-a-client-token
 func (c *Client) CreateClientToken(ctx context.Context, action string, customer *Customer) (*ClientToken, error) {
-	body := ClientTokenRequest{Action: action}
+	body, err := newClientTokenRequest(action, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.createClientToken(ctx, body)
+}
+
+// CreateClientTokenForAction creates a client token for a drop-in component
+// action
+//
+// Customer scoped actions require the customer, which is checked before the
+// request is sent.
+//
+// see: https://docsv2.dwolla.com/#create-a-client-token
+func (c *Client) CreateClientTokenForAction(ctx context.Context, action ClientTokenAction, customer *Customer) (*ClientToken, error) {
+	body, err := newClientTokenRequest(string(action), customer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validate(body); err != nil {
+		return nil, err
+	}
+
+	return c.createClientToken(ctx, body)
+}
+
+// newClientTokenRequest returns a client token request for the action and
+// customer
+func newClientTokenRequest(action string, customer *Customer) (*ClientTokenRequest, error) {
+	body := &ClientTokenRequest{Action: action}
 
 	if customer != nil {
 		if _, ok := customer.Links["self"]; !ok {
@@ -606,11 +656,18 @@ func (c *Client) CreateClientToken(ctx context.Context, action string, customer
 		body.Resource = *NewResource(Links{"customer": Link{Href: customer.Links["self"].Href}}, nil)
 	}
 
+	return body, nil
+}
+
+// createClientToken posts the client token request
+func (c *Client) createClientToken(ctx context.Context, body *ClientTokenRequest) (*ClientToken, error) {
 	var token ClientToken
 
 	if err := c.Post(ctx, "client-tokens", body, nil, &token); err != nil {
 		return nil, err
 	}
 
+	token.issued = time.Now()
+
 	return &token, nil
 }
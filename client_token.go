@@ -0,0 +1,133 @@
+package dwolla
+
+import (
+	"time"
+)
+
+// ClientTokenLifetime is how long dwolla accepts a client, funding source
+// or IAV token after it is issued
+const ClientTokenLifetime = time.Hour
+
+const (
+	// ClientTokenActionCustomerCreate creates a customer
+	ClientTokenActionCustomerCreate ClientTokenAction = "customer.create"
+	// ClientTokenActionCustomerRead displays a customer
+	ClientTokenActionCustomerRead ClientTokenAction = "customer.read"
+	// ClientTokenActionCustomerUpdate updates or upgrades a customer
+	ClientTokenActionCustomerUpdate ClientTokenAction = "customer.update"
+	// ClientTokenActionCustomerDocumentsCreate uploads customer documents
+	ClientTokenActionCustomerDocumentsCreate ClientTokenAction = "customer.documents.create"
+	// ClientTokenActionCustomerBeneficialOwnersCreate creates beneficial
+	// owners
+	ClientTokenActionCustomerBeneficialOwnersCreate ClientTokenAction = "customer.beneficialowners.create"
+	// ClientTokenActionCustomerBeneficialOwnersRead lists beneficial owners
+	ClientTokenActionCustomerBeneficialOwnersRead ClientTokenAction = "customer.beneficialowners.read"
+	// ClientTokenActionCustomerBeneficialOwnersUpdate updates beneficial
+	// owners
+	ClientTokenActionCustomerBeneficialOwnersUpdate ClientTokenAction = "customer.beneficialowners.update"
+	// ClientTokenActionCustomerBeneficialOwnersDelete removes beneficial
+	// owners
+	ClientTokenActionCustomerBeneficialOwnersDelete ClientTokenAction = "customer.beneficialowners.delete"
+	// ClientTokenActionCustomerBeneficialOwnershipRead retrieves the
+	// beneficial ownership status
+	ClientTokenActionCustomerBeneficialOwnershipRead ClientTokenAction = "customer.beneficialownership.read"
+	// ClientTokenActionCustomerBeneficialOwnershipUpdate certifies
+	// beneficial ownership
+	ClientTokenActionCustomerBeneficialOwnershipUpdate ClientTokenAction = "customer.beneficialownership.update"
+	// ClientTokenActionCustomerFundingSourcesCreate creates funding sources
+	ClientTokenActionCustomerFundingSourcesCreate ClientTokenAction = "customer.fundingsources.create"
+	// ClientTokenActionCustomerFundingSourcesRead lists funding sources and
+	// balances
+	ClientTokenActionCustomerFundingSourcesRead ClientTokenAction = "customer.fundingsources.read"
+)
+
+// ClientTokenAction is the drop-in component action a client token is
+// issued for
+type ClientTokenAction string
+
+// clientTokenActions are the actions dwolla's drop-in components use,
+// mapped to whether they act on an existing customer
+var clientTokenActions = map[ClientTokenAction]bool{
+	ClientTokenActionCustomerCreate:                    false,
+	ClientTokenActionCustomerRead:                      true,
+	ClientTokenActionCustomerUpdate:                    true,
+	ClientTokenActionCustomerDocumentsCreate:           true,
+	ClientTokenActionCustomerBeneficialOwnersCreate:    true,
+	ClientTokenActionCustomerBeneficialOwnersRead:      true,
+	ClientTokenActionCustomerBeneficialOwnersUpdate:    true,
+	ClientTokenActionCustomerBeneficialOwnersDelete:    true,
+	ClientTokenActionCustomerBeneficialOwnershipRead:   true,
+	ClientTokenActionCustomerBeneficialOwnershipUpdate: true,
+	ClientTokenActionCustomerFundingSourcesCreate:      true,
+	ClientTokenActionCustomerFundingSourcesRead:        true,
+}
+
+// Known returns true if the action is used by dwolla's drop-in components
+func (a ClientTokenAction) Known() bool {
+	_, ok := clientTokenActions[a]
+
+	return ok
+}
+
+// CustomerScoped returns true if the action acts on an existing customer,
+// so its token must be issued for that customer
+//
+// Actions this package does not know are assumed to be customer scoped.
+func (a ClientTokenAction) CustomerScoped() bool {
+	scoped, ok := clientTokenActions[a]
+
+	return scoped || !ok
+}
+
+// Validate checks that customer scoped actions have a customer link and
+// that customer.create has none
+func (c *ClientTokenRequest) Validate() error {
+	var errs validationErrors
+
+	_, customer := c.Links["customer"]
+	action := ClientTokenAction(c.Action)
+
+	switch {
+	case action == "":
+		errs.add("Required", "/action", "Action required.")
+	case action.CustomerScoped() && !customer:
+		errs.add("Required", "/_links/customer", "Customer required for "+c.Action+".")
+	case !action.CustomerScoped() && customer:
+		errs.add("Invalid", "/_links/customer", "Customer is not allowed for "+c.Action+".")
+	}
+
+	return errs.err()
+}
+
+// ExpiresAt returns when the token expires, estimated from when it was
+// issued since dwolla does not return an expiry
+func (c *ClientToken) ExpiresAt() time.Time {
+	return c.issued.Add(ClientTokenLifetime)
+}
+
+// Expired returns true if the token has expired
+func (c *ClientToken) Expired() bool {
+	return !time.Now().Before(c.ExpiresAt())
+}
+
+// ExpiresAt returns when the token expires, estimated from when it was
+// issued since dwolla does not return an expiry
+func (f *FundingSourceToken) ExpiresAt() time.Time {
+	return f.issued.Add(ClientTokenLifetime)
+}
+
+// Expired returns true if the token has expired
+func (f *FundingSourceToken) Expired() bool {
+	return !time.Now().Before(f.ExpiresAt())
+}
+
+// ExpiresAt returns when the token expires, estimated from when it was
+// issued since dwolla does not return an expiry
+func (i *IAVToken) ExpiresAt() time.Time {
+	return i.issued.Add(ClientTokenLifetime)
+}
+
+// Expired returns true if the token has expired
+func (i *IAVToken) Expired() bool {
+	return !time.Now().Before(i.ExpiresAt())
+}
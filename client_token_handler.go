@@ -0,0 +1,176 @@
+package dwolla
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultClientTokenHandlerMaxBodySize is the default maximum token request
+// body size
+const DefaultClientTokenHandlerMaxBodySize = 1 << 12
+
+// ClientTokenAuthorizeFunc decides whether the request's user may have a
+// token for the action
+//
+// It returns the customer the token is issued for, which must have a self
+// link for customer scoped actions, or an error to deny the token. The
+// customer id is whatever the frontend sent and must not be trusted without
+// checking it belongs to the user.
+type ClientTokenAuthorizeFunc func(r *http.Request, action ClientTokenAction, customerID string) (*Customer, error)
+
+// ClientTokenHandler issues client tokens to a frontend for dwolla's
+// drop-in components
+//
+// The frontend posts a JSON body with an action and, for customer scoped
+// actions, a customerId. To guard against cross-site request forgery only
+// JSON posts are accepted and the request's Origin, or Referer when there is
+// none, must be the handler's own host or one of AllowedOrigins. VerifyCSRF
+// can add a check of the application's own CSRF token.
+//
+// Every token is authorized by Authorize; a handler without one denies all
+// requests. Actions limits the actions issued, which defaults to those
+// known to this package.
+type ClientTokenHandler struct {
+	Client         *Client
+	Authorize      ClientTokenAuthorizeFunc
+	Actions        []ClientTokenAction
+	AllowedOrigins []string
+	VerifyCSRF     func(r *http.Request) bool
+	MaxBodySize    int64
+}
+
+// clientTokenHandlerRequest is the body posted by the frontend
+type clientTokenHandlerRequest struct {
+	Action     ClientTokenAction `json:"action"`
+	CustomerID string            `json:"customerId"`
+}
+
+// clientTokenHandlerResponse is the body returned to the frontend
+type clientTokenHandlerResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ServeHTTP issues a client token
+func (h *ClientTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if !h.allowedOrigin(r) || (h.VerifyCSRF != nil && !h.VerifyCSRF(r)) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	maxBodySize := h.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultClientTokenHandlerMaxBodySize
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var req clientTokenHandlerRequest
+
+	if err := json.Unmarshal(body, &req); err != nil || req.Action == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !h.allowedAction(req.Action) || h.Authorize == nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	customer, err := h.Authorize(r, req.Action, req.CustomerID)
+	if err != nil || (req.Action.CustomerScoped() && customer == nil) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if !req.Action.CustomerScoped() {
+		customer = nil
+	}
+
+	tokenRequest, err := newClientTokenRequest(string(req.Action), customer)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := tokenRequest.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.Client.createClientToken(r.Context(), tokenRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(clientTokenHandlerResponse{Token: token.Token, ExpiresAt: token.ExpiresAt()})
+}
+
+// allowedAction returns true if the handler issues tokens for the action
+func (h *ClientTokenHandler) allowedAction(action ClientTokenAction) bool {
+	if len(h.Actions) == 0 {
+		return action.Known()
+	}
+
+	for _, allowed := range h.Actions {
+		if allowed == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedOrigin returns true if the request came from the handler's host or
+// an allowed origin
+func (h *ClientTokenHandler) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+
+	if origin == "" {
+		referer, err := url.Parse(r.Header.Get("Referer"))
+		if err != nil || referer.Host == "" {
+			return false
+		}
+
+		origin = referer.Scheme + "://" + referer.Host
+	}
+
+	if len(h.AllowedOrigins) == 0 {
+		parsed, err := url.Parse(origin)
+
+		return err == nil && strings.EqualFold(parsed.Host, r.Host)
+	}
+
+	for _, allowed := range h.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package dwolla
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClientTokenAuthorize allows jane to act on testCustomerA
+func testClientTokenAuthorize(r *http.Request, action ClientTokenAction, customerID string) (*Customer, error) {
+	if r.Header.Get("X-User") != "jane" {
+		return nil, errors.New("not signed in")
+	}
+
+	if action.CustomerScoped() && customerID != testCustomerA {
+		return nil, errors.New("not the user's customer")
+	}
+
+	return &Customer{ID: customerID, Resource: Resource{Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/" + customerID}}}}, nil
+}
+
+const testClientTokenURL = "https://app.example.com/dwolla/client-tokens"
+
+// testClientTokenHeader is the header of a same origin request from jane
+var testClientTokenHeader = http.Header{
+	"Content-Type": {"application/json; charset=utf-8"},
+	"Origin":       {"https://app.example.com"},
+	"X-User":       {"jane"},
+}
+
+func TestClientTokenHandler(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+	handler := &ClientTokenHandler{Client: c, Authorize: testClientTokenAuthorize}
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.documents.create","customerId":"`+testCustomerA+`"}`))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+	assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+
+	var body clientTokenHandlerResponse
+
+	assert.Nil(t, json.Unmarshal(res.Body.Bytes(), &body))
+	assert.Equal(t, "4adF858jPeQ9RnojMHdqSD2KwsvmhO7Ti7cI5woOiBGCpH5krY", body.Token)
+	assert.False(t, body.ExpiresAt.IsZero())
+	assert.Len(t, mc.requestLog(), 1)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.create","customerId":"ignored"}`))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Len(t, mc.requestLog(), 2)
+}
+
+func TestClientTokenHandlerCSRF(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+	handler := &ClientTokenHandler{Client: c, Authorize: testClientTokenAuthorize}
+	body := `{"action":"customer.update","customerId":"` + testCustomerA + `"}`
+
+	req := newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Method = http.MethodGet
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, res.Code)
+	assert.Equal(t, http.MethodPost, res.Header().Get("Allow"))
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Header.Set("Origin", "https://evil.example.com")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Header.Del("Origin")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+
+	req.Header.Set("Referer", "https://app.example.com/onboarding")
+	req.Body = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body).Body
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	handler.AllowedOrigins = []string{"https://www.example.com/"}
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Header.Set("Origin", "https://www.example.com")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	handler.VerifyCSRF = func(r *http.Request) bool {
+		return r.Header.Get("X-CSRF-Token") == "s3cret"
+	}
+
+	req = newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, body)
+	req.Header.Set("Origin", "https://www.example.com")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	assert.Len(t, mc.requestLog(), 2)
+}
+
+func TestClientTokenHandlerAuthorization(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+	handler := &ClientTokenHandler{Client: c, Authorize: testClientTokenAuthorize}
+
+	for _, test := range []struct {
+		body   string
+		user   string
+		status int
+	}{
+		{`{"action":"customer.update","customerId":"` + testCustomerB + `"}`, "jane", http.StatusForbidden},
+		{`{"action":"customer.update","customerId":"` + testCustomerA + `"}`, "john", http.StatusForbidden},
+		{`{"action":"customer.transfers.create","customerId":"` + testCustomerA + `"}`, "jane", http.StatusForbidden},
+		{`{"action":""}`, "jane", http.StatusBadRequest},
+		{`not json`, "jane", http.StatusBadRequest},
+	} {
+		req := newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, test.body)
+		req.Header.Set("X-User", test.user)
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+
+		assert.Equal(t, test.status, res.Code, test.body)
+	}
+
+	assert.Len(t, mc.requestLog(), 0)
+
+	handler.Actions = []ClientTokenAction{ClientTokenActionCustomerFundingSourcesCreate}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.update","customerId":"`+testCustomerA+`"}`))
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.fundingsources.create","customerId":"`+testCustomerA+`"}`))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	handler.Authorize = nil
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.fundingsources.create","customerId":"`+testCustomerA+`"}`))
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	assert.Len(t, mc.requestLog(), 1)
+}
+
+func TestClientTokenHandlerError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {400, filepath.Join("testdata", "validation-error.json")},
+	})
+	handler := &ClientTokenHandler{Client: c, Authorize: testClientTokenAuthorize}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.create"}`))
+
+	assert.Equal(t, http.StatusBadGateway, res.Code)
+	assert.Len(t, mc.requestLog(), 1)
+
+	handler.Authorize = func(r *http.Request, action ClientTokenAction, customerID string) (*Customer, error) {
+		return &Customer{ID: customerID}, nil
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.update","customerId":"`+testCustomerA+`"}`))
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Len(t, mc.requestLog(), 1)
+
+	handler.MaxBodySize = 8
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestRequest(http.MethodPost, testClientTokenURL, testClientTokenHeader, `{"action":"customer.create"}`))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
@@ -0,0 +1,127 @@
+package dwolla
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientTokenAction(t *testing.T) {
+	assert.True(t, ClientTokenActionCustomerCreate.Known())
+	assert.False(t, ClientTokenActionCustomerCreate.CustomerScoped())
+
+	for _, action := range []ClientTokenAction{
+		ClientTokenActionCustomerUpdate,
+		ClientTokenActionCustomerDocumentsCreate,
+		ClientTokenActionCustomerBeneficialOwnersCreate,
+		ClientTokenActionCustomerBeneficialOwnershipUpdate,
+		ClientTokenActionCustomerFundingSourcesCreate,
+	} {
+		assert.True(t, action.Known(), string(action))
+		assert.True(t, action.CustomerScoped(), string(action))
+	}
+
+	assert.False(t, ClientTokenAction("customer.transfers.create").Known())
+	assert.True(t, ClientTokenAction("customer.transfers.create").CustomerScoped())
+}
+
+func TestClientTokenRequestValidate(t *testing.T) {
+	customer := Links{"customer": Link{Href: "https://api-sandbox.dwolla.com/customers/" + testCustomerA}}
+
+	assert.Nil(t, (&ClientTokenRequest{Action: string(ClientTokenActionCustomerCreate)}).Validate())
+	assert.Nil(t, (&ClientTokenRequest{Resource: Resource{Links: customer}, Action: string(ClientTokenActionCustomerUpdate)}).Validate())
+
+	assert.Equal(t, []string{"/action"}, validationErrorPaths((&ClientTokenRequest{}).Validate()))
+	assert.Equal(t, []string{"/_links/customer"}, validationErrorPaths((&ClientTokenRequest{Action: string(ClientTokenActionCustomerDocumentsCreate)}).Validate()))
+	assert.Equal(t, []string{"/_links/customer"}, validationErrorPaths((&ClientTokenRequest{Resource: Resource{Links: customer}, Action: string(ClientTokenActionCustomerCreate)}).Validate()))
+}
+
+func TestClientCreateClientTokenForAction(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+
+	customer := &Customer{Resource: Resource{Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/" + testCustomerA}}}}
+
+	token, err := c.CreateClientTokenForAction(ctx, ClientTokenActionCustomerUpdate, customer)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4adF858jPeQ9RnojMHdqSD2KwsvmhO7Ti7cI5woOiBGCpH5krY", token.Token)
+	assert.False(t, token.Expired())
+	assert.True(t, token.ExpiresAt().After(time.Now().Add(ClientTokenLifetime-time.Minute)))
+
+	reader, err := mc.requestLog()[0].GetBody()
+	assert.Nil(t, err)
+
+	data, _ := ioutil.ReadAll(reader)
+
+	assert.Contains(t, string(data), `"action":"customer.update"`)
+	assert.Contains(t, string(data), `"customer":{"href":"https://api-sandbox.dwolla.com/customers/`+testCustomerA+`"}`)
+
+	token, err = c.CreateClientTokenForAction(ctx, ClientTokenActionCustomerCreate, nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, token)
+}
+
+func TestClientCreateClientTokenForActionError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+
+	token, err := c.CreateClientTokenForAction(ctx, ClientTokenActionCustomerDocumentsCreate, nil)
+
+	assert.Equal(t, []string{"/_links/customer"}, validationErrorPaths(err))
+	assert.Nil(t, token)
+
+	token, err = c.CreateClientTokenForAction(ctx, ClientTokenActionCustomerUpdate, &Customer{})
+
+	assert.Error(t, err)
+	assert.Equal(t, "No self resource link", err.Error())
+	assert.Nil(t, token)
+	assert.Len(t, mc.requestLog(), 0)
+}
+
+func TestClientCreateClientToken(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /client-tokens": {200, filepath.Join("testdata", "client-token.json")},
+	})
+
+	token, err := c.CreateClientToken(ctx, "customer.transfers.create", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4adF858jPeQ9RnojMHdqSD2KwsvmhO7Ti7cI5woOiBGCpH5krY", token.Token)
+	assert.False(t, token.Expired())
+	assert.Len(t, mc.requestLog(), 1)
+
+	token, err = c.CreateClientToken(ctx, "customer.update", &Customer{})
+
+	assert.Error(t, err)
+	assert.Nil(t, token)
+	assert.Len(t, mc.requestLog(), 1)
+}
+
+func TestClientTokenExpired(t *testing.T) {
+	token := &ClientToken{issued: time.Now().Add(-ClientTokenLifetime)}
+	assert.True(t, token.Expired())
+
+	source := &FundingSourceToken{issued: time.Now().Add(-time.Minute)}
+	assert.False(t, source.Expired())
+
+	iav := &IAVToken{issued: time.Now().Add(-2 * ClientTokenLifetime)}
+	assert.True(t, iav.Expired())
+}
+
+func TestCustomerRetrieveIAVTokenExpiry(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "iav-token.json"))
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/" + testCustomerA}}}}
+
+	token, err := customer.RetrieveIAVToken(ctx)
+
+	assert.Nil(t, err)
+	assert.False(t, token.Expired())
+	assert.True(t, token.ExpiresAt().After(time.Now()))
+}
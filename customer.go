@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -72,22 +73,23 @@ type CustomerType string
 // Customer is a dwolla customer
 type Customer struct {
 	Resource
-	ID           string         `json:"id"`
-	FirstName    string         `json:"firstName"`
-	LastName     string         `json:"lastName"`
-	Email        string         `json:"email"`
-	Type         CustomerType   `json:"type"`
-	Status       CustomerStatus `json:"status"`
-	Created      string         `json:"created"`
-	Address1     string         `json:"address1"`
-	Address2     string         `json:"address2"`
-	City         string         `json:"city"`
-	State        string         `json:"state"`
-	PostalCode   string         `json:"postalCode"`
-	Phone        string         `json:"phone"`
-	BusinessName string         `json:"businessName"`
-	BusinessType string         `json:"businessType"`
-	Controller   Controller     `json:"controller"`
+	ID            string         `json:"id"`
+	FirstName     string         `json:"firstName"`
+	LastName      string         `json:"lastName"`
+	Email         string         `json:"email"`
+	Type          CustomerType   `json:"type"`
+	Status        CustomerStatus `json:"status"`
+	Created       string         `json:"created"`
+	Address1      string         `json:"address1"`
+	Address2      string         `json:"address2"`
+	City          string         `json:"city"`
+	State         string         `json:"state"`
+	PostalCode    string         `json:"postalCode"`
+	Phone         string         `json:"phone"`
+	BusinessName  string         `json:"businessName"`
+	BusinessType  string         `json:"businessType"`
+	Controller    Controller     `json:"controller"`
+	CorrelationID string         `json:"correlationId,omitempty"`
 }
 
 // Customers is a collection of customers
@@ -123,6 +125,7 @@ type CustomerRequest struct {
 	EIN                    string             `json:"ein,omitempty"`
 	Website                string             `json:"website,omitempty"`
 	Controller             *ControllerRequest `json:"controller,omitempty"`
+	CorrelationID          string             `json:"correlationId,omitempty"`
 }
 
 // ControllerRequest is a controller of a business create/update request
@@ -139,13 +142,20 @@ type ControllerRequest struct {
 // IAVToken is a instant account verification token
 type IAVToken struct {
 	Resource
-	Token string `json:"token"`
+	Token  string `json:"token"`
+	issued time.Time
 }
 
 // Create creates a dwolla customer
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
 func (c *CustomerServiceOp) Create(ctx context.Context, body *CustomerRequest) (*Customer, error) {
 	var customer Customer
 
+	if err := c.client.validate(body); err != nil {
+		return nil, err
+	}
+
 	if err := c.client.Post(ctx, "customers", body, nil, &customer); err != nil {
 		return nil, err
 	}
@@ -195,6 +205,10 @@ func (c *CustomerServiceOp) Retrieve(ctx context.Context, id string) (*Customer,
 func (c *CustomerServiceOp) Update(ctx context.Context, id string, body *CustomerRequest) (*Customer, error) {
 	var customer Customer
 
+	if err := c.client.validate(body); err != nil {
+		return nil, err
+	}
+
 	if err := c.client.Post(ctx, fmt.Sprintf("customers/%s", id), body, nil, &customer); err != nil {
 		return nil, err
 	}
@@ -204,8 +218,24 @@ func (c *CustomerServiceOp) Update(ctx context.Context, id string, body *Custome
 	return &customer, nil
 }
 
+// BalanceFundingSource returns the customer's balance funding source
+//
+// Only verified customers have a balance. ErrNoBalanceFundingSource is
+// returned when the customer has none.
+func (c *Customer) BalanceFundingSource(ctx context.Context) (*FundingSource, error) {
+	sources, err := c.ListFundingSources(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return sources.Balance()
+}
+
 // CertifyBeneficialOwnership certifies beneficial ownership
 //
+// Use CertifyIfReady to check that the beneficial owners are complete
+// first.
+//
 // see: https://docsv2.dwolla.com/#certify-beneficial-ownership
 func (c *Customer) CertifyBeneficialOwnership(ctx context.Context) error {
 	if _, ok := c.Links["certify-beneficial-ownership"]; !ok {
@@ -252,6 +282,10 @@ func (c *Customer) CreateBeneficialOwner(ctx context.Context, body *BeneficialOw
 		return nil, errors.New("No beneficial owners resource link")
 	}
 
+	if err := c.client.validate(body); err != nil {
+		return nil, err
+	}
+
 	if err := c.client.Post(ctx, c.Links["beneficial-owners"].Href, body, nil, &owner); err != nil {
 		return nil, err
 	}
@@ -261,8 +295,52 @@ func (c *Customer) CreateBeneficialOwner(ctx context.Context, body *BeneficialOw
 	return &owner, nil
 }
 
+// CreateExchange creates an exchange for the customer from an exchange
+// partner's account authorization
+//
+// see: https://docsv2.dwolla.com/#create-an-exchange-for-a-customer
+func (c *Customer) CreateExchange(ctx context.Context, body *ExchangeRequest) (*Exchange, error) {
+	var exchange Exchange
+
+	if _, ok := c.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	if err := c.client.Post(ctx, fmt.Sprintf("%s/exchanges", c.Links["self"].Href), body, nil, &exchange); err != nil {
+		return nil, err
+	}
+
+	exchange.client = c.client
+
+	return &exchange, nil
+}
+
+// CreateExchangeSession creates an exchange session for the customer
+//
+// see: https://docsv2.dwolla.com/#create-an-exchange-session-for-a-customer
+func (c *Customer) CreateExchangeSession(ctx context.Context, body *ExchangeSessionRequest) (*ExchangeSession, error) {
+	var session ExchangeSession
+
+	if _, ok := c.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	if err := c.client.Post(ctx, fmt.Sprintf("%s/exchange-sessions", c.Links["self"].Href), body, nil, &session); err != nil {
+		return nil, err
+	}
+
+	session.client = c.client
+
+	return &session, nil
+}
+
 // CreateFundingSource creates a funding source for the customer
 //
+// Bank account details are validated before the request is sent, and checked
+// against Client.ACHDirectory when one is loaded. When
+// Client.RequireOnDemandAuthorization is set the request must have an
+// on-demand authorization attached.
+//
 // see: https://docsv2.dwolla.com/#create-a-funding-source-for-a-customer
 func (c *Customer) CreateFundingSource(ctx context.Context, body *FundingSourceRequest) (*FundingSource, error) {
 	var source FundingSource
@@ -271,6 +349,14 @@ func (c *Customer) CreateFundingSource(ctx context.Context, body *FundingSourceR
 		return nil, errors.New("No funding sources resource link")
 	}
 
+	if err := c.client.checkOnDemandAuthorization(body); err != nil {
+		return nil, err
+	}
+
+	if err := c.client.validateFundingSource(body); err != nil {
+		return nil, err
+	}
+
 	if err := c.client.Post(ctx, c.Links["funding-sources"].Href, body, nil, &source); err != nil {
 		return nil, err
 	}
@@ -295,10 +381,23 @@ func (c *Customer) CreateFundingSourceToken(ctx context.Context) (*FundingSource
 	}
 
 	token.client = c.client
+	token.issued = time.Now()
 
 	return &token, nil
 }
 
+// CreateLabel creates a label for the customer
+//
+// see: https://docsv2.dwolla.com/#create-a-label
+func (c *Customer) CreateLabel(ctx context.Context, body *LabelRequest) (*Label, error) {
+	id, err := c.id()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.Label.Create(ctx, id, body)
+}
+
 // Deactivate deactivates a dwolla customer
 func (c *Customer) Deactivate(ctx context.Context) error {
 	if _, ok := c.Links["deactivate"]; !ok {
@@ -375,6 +474,29 @@ func (c *Customer) ListDocuments(ctx context.Context) (*Documents, error) {
 	return &documents, nil
 }
 
+// ListExchanges returns the customer's exchanges
+//
+// see: https://docsv2.dwolla.com/#list-exchanges-for-a-customer
+func (c *Customer) ListExchanges(ctx context.Context) (*Exchanges, error) {
+	var exchanges Exchanges
+
+	if _, ok := c.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	if err := c.client.Get(ctx, fmt.Sprintf("%s/exchanges", c.Links["self"].Href), nil, nil, &exchanges); err != nil {
+		return nil, err
+	}
+
+	exchanges.client = c.client
+
+	for i := range exchanges.Embedded["exchanges"] {
+		exchanges.Embedded["exchanges"][i].client = c.client
+	}
+
+	return &exchanges, nil
+}
+
 // ListFundingSources returns the customer's funding sources
 //
 // see: https://docsv2.dwolla.com/#list-funding-sources-for-a-customer
@@ -401,6 +523,18 @@ func (c *Customer) ListFundingSources(ctx context.Context, removed bool) (*Fundi
 	return &sources, nil
 }
 
+// ListLabels returns the customer's labels
+//
+// see: https://docsv2.dwolla.com/#list-labels-for-a-customer
+func (c *Customer) ListLabels(ctx context.Context, params *url.Values) (*Labels, error) {
+	id, err := c.id()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.Label.List(ctx, id, params)
+}
+
 // ListMassPayments returns the customer's mass payments
 //
 // see: https://docsv2.dwolla.com/#list-mass-payments-for-a-customer
@@ -493,6 +627,9 @@ func (c *Customer) RetrieveIAVToken(ctx context.Context) (*IAVToken, error) {
 		return nil, err
 	}
 
+	token.client = c.client
+	token.issued = time.Now()
+
 	return &token, nil
 }
 
@@ -527,6 +664,10 @@ func (c *Customer) Update(ctx context.Context, body *CustomerRequest) error {
 		return errors.New("No self resource link")
 	}
 
+	if err := c.client.validate(body); err != nil {
+		return err
+	}
+
 	return c.client.Post(ctx, c.Links["self"].Href, body, nil, c)
 }
 
@@ -553,3 +694,18 @@ func (c *Customer) VerifyControllerAndBusiness() bool {
 	_, ok := c.Links["verify-controller-and-business-with-document"]
 	return ok
 }
+
+// id returns the customer's id, taken from its self link when it has none
+func (c *Customer) id() (string, error) {
+	if c.ID != "" {
+		return c.ID, nil
+	}
+
+	if _, ok := c.Links["self"]; !ok {
+		return "", errors.New("No self resource link")
+	}
+
+	href := c.Links["self"].Href
+
+	return href[strings.LastIndex(href, "/")+1:], nil
+}
@@ -0,0 +1,422 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// BusinessTypeCorporation is a corporation
+	BusinessTypeCorporation = "corporation"
+	// BusinessTypeLLC is a limited liability company
+	BusinessTypeLLC = "llc"
+	// BusinessTypePartnership is a partnership
+	BusinessTypePartnership = "partnership"
+	// BusinessTypeSoleProprietorship is a sole proprietorship
+	BusinessTypeSoleProprietorship = "soleProprietorship"
+)
+
+// CustomerRequestBuilder builds a validated customer request
+//
+// CustomerRequest implements it directly, and each customer type has its
+// own request type that only exposes the fields dwolla accepts for it.
+type CustomerRequestBuilder interface {
+	Build() (*CustomerRequest, error)
+}
+
+// Build returns the customer request as is
+func (c *CustomerRequest) Build() (*CustomerRequest, error) {
+	return c, nil
+}
+
+// CreateCustomerFromBuilder creates a dwolla customer from a type specific
+// request, such as PersonalVerifiedCustomerRequest
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+func (c *Client) CreateCustomerFromBuilder(ctx context.Context, builder CustomerRequestBuilder) (*Customer, error) {
+	request, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Customer.Create(ctx, request)
+}
+
+// UnverifiedCustomerRequest is a request to create an unverified customer
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+type UnverifiedCustomerRequest struct {
+	FirstName     string
+	LastName      string
+	Email         string
+	IPAddress     string
+	BusinessName  string
+	CorrelationID string
+}
+
+// Validate checks the request's required fields
+func (r *UnverifiedCustomerRequest) Validate() error {
+	var errs validationErrors
+
+	requireFields(&errs, map[string]string{
+		"/firstName": r.FirstName,
+		"/lastName":  r.LastName,
+		"/email":     r.Email,
+	})
+
+	return errs.err()
+}
+
+// Build returns the validated customer request
+func (r *UnverifiedCustomerRequest) Build() (*CustomerRequest, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CustomerRequest{
+		FirstName:     r.FirstName,
+		LastName:      r.LastName,
+		Email:         r.Email,
+		IPAddress:     r.IPAddress,
+		BusinessName:  r.BusinessName,
+		CorrelationID: r.CorrelationID,
+		Type:          CustomerTypeUnverified,
+	}, nil
+}
+
+// ReceiveOnlyCustomerRequest is a request to create a receive-only customer
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+type ReceiveOnlyCustomerRequest struct {
+	FirstName     string
+	LastName      string
+	Email         string
+	IPAddress     string
+	BusinessName  string
+	CorrelationID string
+}
+
+// Validate checks the request's required fields
+func (r *ReceiveOnlyCustomerRequest) Validate() error {
+	var errs validationErrors
+
+	requireFields(&errs, map[string]string{
+		"/firstName": r.FirstName,
+		"/lastName":  r.LastName,
+		"/email":     r.Email,
+	})
+
+	return errs.err()
+}
+
+// Build returns the validated customer request
+func (r *ReceiveOnlyCustomerRequest) Build() (*CustomerRequest, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CustomerRequest{
+		FirstName:     r.FirstName,
+		LastName:      r.LastName,
+		Email:         r.Email,
+		IPAddress:     r.IPAddress,
+		BusinessName:  r.BusinessName,
+		CorrelationID: r.CorrelationID,
+		Type:          CustomerTypeReceiveOnly,
+	}, nil
+}
+
+// PersonalVerifiedCustomerRequest is a request to create a personal
+// verified customer
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+type PersonalVerifiedCustomerRequest struct {
+	FirstName     string
+	LastName      string
+	Email         string
+	IPAddress     string
+	DateOfBirth   string
+	SSN           string
+	Phone         string
+	Address1      string
+	Address2      string
+	City          string
+	State         string
+	PostalCode    string
+	CorrelationID string
+}
+
+// Validate checks the request's required fields
+func (r *PersonalVerifiedCustomerRequest) Validate() error {
+	var errs validationErrors
+
+	requireFields(&errs, map[string]string{
+		"/firstName":   r.FirstName,
+		"/lastName":    r.LastName,
+		"/email":       r.Email,
+		"/dateOfBirth": r.DateOfBirth,
+		"/ssn":         r.SSN,
+		"/address1":    r.Address1,
+		"/city":        r.City,
+		"/state":       r.State,
+		"/postalCode":  r.PostalCode,
+	})
+
+	return errs.err()
+}
+
+// Build returns the validated customer request
+func (r *PersonalVerifiedCustomerRequest) Build() (*CustomerRequest, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CustomerRequest{
+		FirstName:     r.FirstName,
+		LastName:      r.LastName,
+		Email:         r.Email,
+		IPAddress:     r.IPAddress,
+		Type:          CustomerTypePersonal,
+		DateOfBirth:   r.DateOfBirth,
+		SSN:           r.SSN,
+		Phone:         r.Phone,
+		Address1:      r.Address1,
+		Address2:      r.Address2,
+		City:          r.City,
+		State:         r.State,
+		PostalCode:    r.PostalCode,
+		CorrelationID: r.CorrelationID,
+	}, nil
+}
+
+// SoleProprietorshipRequest is a request to create a business verified
+// customer for a sole proprietorship
+//
+// Sole proprietorships are verified using the owner's personal details and
+// do not have a controller.
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+type SoleProprietorshipRequest struct {
+	FirstName              string
+	LastName               string
+	Email                  string
+	IPAddress              string
+	DateOfBirth            string
+	SSN                    string
+	Phone                  string
+	Address1               string
+	Address2               string
+	City                   string
+	State                  string
+	PostalCode             string
+	BusinessName           string
+	DoingBusinessAs        string
+	BusinessClassification string
+	EIN                    string
+	Website                string
+	CorrelationID          string
+}
+
+// Validate checks the request's required fields
+func (r *SoleProprietorshipRequest) Validate() error {
+	var errs validationErrors
+
+	requireFields(&errs, map[string]string{
+		"/firstName":              r.FirstName,
+		"/lastName":               r.LastName,
+		"/email":                  r.Email,
+		"/dateOfBirth":            r.DateOfBirth,
+		"/ssn":                    r.SSN,
+		"/address1":               r.Address1,
+		"/city":                   r.City,
+		"/state":                  r.State,
+		"/postalCode":             r.PostalCode,
+		"/businessName":           r.BusinessName,
+		"/businessClassification": r.BusinessClassification,
+	})
+
+	return errs.err()
+}
+
+// Build returns the validated customer request
+func (r *SoleProprietorshipRequest) Build() (*CustomerRequest, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &CustomerRequest{
+		FirstName:              r.FirstName,
+		LastName:               r.LastName,
+		Email:                  r.Email,
+		IPAddress:              r.IPAddress,
+		Type:                   CustomerTypeBusiness,
+		DateOfBirth:            r.DateOfBirth,
+		SSN:                    r.SSN,
+		Phone:                  r.Phone,
+		Address1:               r.Address1,
+		Address2:               r.Address2,
+		City:                   r.City,
+		State:                  r.State,
+		PostalCode:             r.PostalCode,
+		BusinessClassification: r.BusinessClassification,
+		BusinessType:           BusinessTypeSoleProprietorship,
+		BusinessName:           r.BusinessName,
+		DoingBusinessAs:        r.DoingBusinessAs,
+		EIN:                    r.EIN,
+		Website:                r.Website,
+		CorrelationID:          r.CorrelationID,
+	}, nil
+}
+
+// BusinessVerifiedCustomerRequest is a request to create a business
+// verified customer with a controller
+//
+// The name and email are those of the account admin creating the customer.
+//
+// see: https://docsv2.dwolla.com/#create-a-customer
+type BusinessVerifiedCustomerRequest struct {
+	FirstName              string
+	LastName               string
+	Email                  string
+	IPAddress              string
+	Phone                  string
+	Address1               string
+	Address2               string
+	City                   string
+	State                  string
+	PostalCode             string
+	BusinessName           string
+	DoingBusinessAs        string
+	BusinessType           string
+	BusinessClassification string
+	EIN                    string
+	Website                string
+	Controller             ControllerRequest
+	CorrelationID          string
+}
+
+// Validate checks the request's required fields
+func (r *BusinessVerifiedCustomerRequest) Validate() error {
+	var errs validationErrors
+
+	requireFields(&errs, map[string]string{
+		"/firstName":                   r.FirstName,
+		"/lastName":                    r.LastName,
+		"/email":                       r.Email,
+		"/address1":                    r.Address1,
+		"/city":                        r.City,
+		"/state":                       r.State,
+		"/postalCode":                  r.PostalCode,
+		"/businessName":                r.BusinessName,
+		"/businessType":                r.BusinessType,
+		"/businessClassification":      r.BusinessClassification,
+		"/ein":                         r.EIN,
+		"/controller/firstName":        r.Controller.FirstName,
+		"/controller/lastName":         r.Controller.LastName,
+		"/controller/title":            r.Controller.Title,
+		"/controller/dateOfBirth":      r.Controller.DateOfBirth,
+		"/controller/address/address1": r.Controller.Address.Address1,
+		"/controller/address/city":     r.Controller.Address.City,
+		"/controller/address/country":  r.Controller.Address.Country,
+		"/controller/address/stateProvinceRegion": r.Controller.Address.StateProvinceRegion,
+	})
+
+	switch r.BusinessType {
+	case "", BusinessTypeCorporation, BusinessTypeLLC, BusinessTypePartnership:
+	case BusinessTypeSoleProprietorship:
+		errs.add("Invalid", "/businessType", "Use SoleProprietorshipRequest for sole proprietorships.")
+	default:
+		errs.add("Invalid", "/businessType", "Business type must be corporation, llc or partnership.")
+	}
+
+	if r.Controller.Address.Country == "US" && r.Controller.SSN == "" {
+		errs.add("Required", "/controller/ssn", "controller ssn required.")
+	}
+
+	if r.Controller.Address.Country != "" && r.Controller.Address.Country != "US" && r.Controller.Passport == nil {
+		errs.add("Required", "/controller/passport", "controller passport required.")
+	}
+
+	return errs.err()
+}
+
+// Build returns the validated customer request
+func (r *BusinessVerifiedCustomerRequest) Build() (*CustomerRequest, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	controller := r.Controller
+
+	return &CustomerRequest{
+		FirstName:              r.FirstName,
+		LastName:               r.LastName,
+		Email:                  r.Email,
+		IPAddress:              r.IPAddress,
+		Type:                   CustomerTypeBusiness,
+		Phone:                  r.Phone,
+		Address1:               r.Address1,
+		Address2:               r.Address2,
+		City:                   r.City,
+		State:                  r.State,
+		PostalCode:             r.PostalCode,
+		BusinessClassification: r.BusinessClassification,
+		BusinessType:           r.BusinessType,
+		BusinessName:           r.BusinessName,
+		DoingBusinessAs:        r.DoingBusinessAs,
+		EIN:                    r.EIN,
+		Website:                r.Website,
+		Controller:             &controller,
+		CorrelationID:          r.CorrelationID,
+	}, nil
+}
+
+// UpgradeToVerified upgrades an unverified customer to a verified customer
+//
+// The request must build a personal or business verified customer. The
+// customer is refreshed with the response.
+//
+// see: https://docsv2.dwolla.com/#upgrade-an-unverified-customer-to-verified-customer
+func (c *Customer) UpgradeToVerified(ctx context.Context, body CustomerRequestBuilder) error {
+	if _, ok := c.Links["self"]; !ok {
+		return errors.New("No self resource link")
+	}
+
+	if c.Type != CustomerTypeUnverified {
+		return fmt.Errorf("Only unverified customers can be upgraded, customer is %s", c.Type)
+	}
+
+	request, err := body.Build()
+	if err != nil {
+		return err
+	}
+
+	if request.Type != CustomerTypePersonal && request.Type != CustomerTypeBusiness {
+		return fmt.Errorf("Unverified customers can only be upgraded to personal or business, not %s", request.Type)
+	}
+
+	if err := c.client.validate(request); err != nil {
+		return err
+	}
+
+	var customer Customer
+
+	if err := c.client.Post(ctx, c.Links["self"].Href, request, nil, &customer); err != nil {
+		return err
+	}
+
+	customer.client = c.client
+	*c = customer
+
+	return nil
+}
+
+// requireFields records a required error for each empty field
+func requireFields(errs *validationErrors, fields map[string]string) {
+	for _, path := range sortedKeys(fields) {
+		if fields[path] == "" {
+			errs.add("Required", path, fmt.Sprintf("%s required.", path[1:]))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerRequestBuild(t *testing.T) {
+	req := &CustomerRequest{FirstName: "Jane"}
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res, req)
+}
+
+func TestUnverifiedCustomerRequestBuild(t *testing.T) {
+	req := &UnverifiedCustomerRequest{FirstName: "Jane", LastName: "Doe", Email: "janedoe@nomail.com", CorrelationID: "abc"}
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypeUnverified)
+	assert.Equal(t, res.CorrelationID, "abc")
+
+	req.Email = ""
+	res, err = req.Build()
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	assert.Equal(t, validationErrorPaths(err), []string{"/email"})
+}
+
+func TestReceiveOnlyCustomerRequestBuild(t *testing.T) {
+	req := &ReceiveOnlyCustomerRequest{FirstName: "Jane", LastName: "Doe", Email: "janedoe@nomail.com"}
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypeReceiveOnly)
+
+	_, err = (&ReceiveOnlyCustomerRequest{}).Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/email", "/firstName", "/lastName"})
+}
+
+func TestPersonalVerifiedCustomerRequestBuild(t *testing.T) {
+	req := newTestPersonalVerifiedCustomerRequest()
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypePersonal)
+	assert.Equal(t, res.SSN, "1234")
+	assert.Nil(t, res.Controller)
+
+	req.SSN = ""
+	req.DateOfBirth = ""
+	_, err = req.Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/dateOfBirth", "/ssn"})
+}
+
+func TestSoleProprietorshipRequestBuild(t *testing.T) {
+	req := &SoleProprietorshipRequest{
+		FirstName:              "Jane",
+		LastName:               "Doe",
+		Email:                  "janedoe@nomail.com",
+		DateOfBirth:            "1970-01-01",
+		SSN:                    "1234",
+		Address1:               "99-99 33rd St",
+		City:                   "Some City",
+		State:                  "NY",
+		PostalCode:             "11101",
+		BusinessName:           "Jane's Bakery",
+		BusinessClassification: "9ed3f670-7d6f-11e3-b1ce-5404a6144203",
+	}
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypeBusiness)
+	assert.Equal(t, res.BusinessType, BusinessTypeSoleProprietorship)
+	assert.Nil(t, res.Controller)
+
+	req.BusinessName = ""
+	_, err = req.Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/businessName"})
+}
+
+func TestBusinessVerifiedCustomerRequestBuild(t *testing.T) {
+	req := newTestBusinessVerifiedCustomerRequest()
+	res, err := req.Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypeBusiness)
+	assert.Equal(t, res.BusinessType, BusinessTypeLLC)
+	assert.Equal(t, res.Controller.Title, "CEO")
+	assert.Empty(t, res.SSN)
+
+	req.BusinessType = BusinessTypeSoleProprietorship
+	_, err = req.Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/businessType"})
+
+	req = newTestBusinessVerifiedCustomerRequest()
+	req.Controller.SSN = ""
+	req.Controller.Address.Country = "CA"
+	_, err = req.Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/controller/passport"})
+
+	req.Controller.Address.Country = "US"
+	_, err = req.Build()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/controller/ssn"})
+}
+
+func TestClientCreateCustomerFromBuilder(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "customer.json"))
+	res, err := c.CreateCustomerFromBuilder(ctx, &UnverifiedCustomerRequest{
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Email:     "janedoe@nomail.com",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Type, CustomerTypeUnverified)
+
+	res, err = c.CreateCustomerFromBuilder(ctx, &PersonalVerifiedCustomerRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestCustomerUpgradeToVerified(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer-verified.json"))
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}}, Type: CustomerTypeUnverified}
+	err := customer.UpgradeToVerified(ctx, newTestPersonalVerifiedCustomerRequest())
+
+	assert.Nil(t, err)
+	assert.Equal(t, customer.Type, CustomerTypePersonal)
+	assert.Equal(t, customer.Status, CustomerStatusVerified)
+}
+
+func TestCustomerUpgradeToVerifiedError(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer-verified.json"))
+
+	customer := &Customer{Resource: Resource{client: c}, Type: CustomerTypeUnverified}
+	assert.Error(t, customer.UpgradeToVerified(ctx, newTestPersonalVerifiedCustomerRequest()))
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	assert.Error(t, customer.UpgradeToVerified(ctx, &UnverifiedCustomerRequest{FirstName: "Jane", LastName: "Doe", Email: "janedoe@nomail.com"}))
+	assert.Error(t, customer.UpgradeToVerified(ctx, &PersonalVerifiedCustomerRequest{}))
+
+	customer.Type = CustomerTypePersonal
+	assert.Error(t, customer.UpgradeToVerified(ctx, newTestPersonalVerifiedCustomerRequest()))
+	assert.Equal(t, customer.Type, CustomerTypePersonal)
+}
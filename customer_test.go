@@ -269,7 +269,7 @@ func TestCustomerCreateFundingSource(t *testing.T) {
 
 	customer := &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/funding-sources"}}}}
 	source, err := customer.CreateFundingSource(ctx, &FundingSourceRequest{
-		RoutingNumber:   "1234567890",
+		RoutingNumber:   "222222226",
 		AccountNumber:   "1234567890",
 		BankAccountType: FundingSourceBankAccountTypeChecking,
 		Name:            "Test Checking Account",
@@ -284,7 +284,7 @@ func TestCustomerCreateFundingSourceError(t *testing.T) {
 
 	customer := &Customer{Resource: Resource{client: c}}
 	res, err := customer.CreateFundingSource(ctx, &FundingSourceRequest{
-		RoutingNumber:   "1234567890",
+		RoutingNumber:   "222222226",
 		AccountNumber:   "1234567890",
 		BankAccountType: FundingSourceBankAccountTypeChecking,
 		Name:            "Test Checking Account",
@@ -296,7 +296,7 @@ func TestCustomerCreateFundingSourceError(t *testing.T) {
 
 	customer = &Customer{Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/funding-sources"}}}}
 	res, err = customer.CreateFundingSource(ctx, &FundingSourceRequest{
-		RoutingNumber:   "1234567890",
+		RoutingNumber:   "222222226",
 		AccountNumber:   "1234567890",
 		BankAccountType: FundingSourceBankAccountTypeChecking,
 		Name:            "Test Checking Account",
@@ -0,0 +1,315 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	// CustomerActionNone is when the customer needs no further action
+	CustomerActionNone CustomerAction = "none"
+	// CustomerActionRetry is when verification must be retried with the
+	// full 9-digit SSN
+	CustomerActionRetry CustomerAction = "retry"
+	// CustomerActionAnswerKBA is when the customer must answer knowledge
+	// based authentication questions
+	CustomerActionAnswerKBA CustomerAction = "answer-kba"
+	// CustomerActionUploadPersonalDocument is when a personal customer must
+	// upload an identity document
+	CustomerActionUploadPersonalDocument CustomerAction = "upload-personal-document"
+	// CustomerActionUploadControllerDocument is when a business controller
+	// must upload an identity document
+	CustomerActionUploadControllerDocument CustomerAction = "upload-controller-document"
+	// CustomerActionUploadBusinessDocument is when a business must upload a
+	// business document
+	CustomerActionUploadBusinessDocument CustomerAction = "upload-business-document"
+	// CustomerActionUploadControllerAndBusinessDocument is when both the
+	// controller and the business must upload documents
+	CustomerActionUploadControllerAndBusinessDocument CustomerAction = "upload-controller-and-business-document"
+	// CustomerActionAddBeneficialOwners is when a business must add
+	// beneficial owners
+	CustomerActionAddBeneficialOwners CustomerAction = "add-beneficial-owners"
+	// CustomerActionCertifyOwnership is when a business must certify
+	// beneficial ownership
+	CustomerActionCertifyOwnership CustomerAction = "certify-ownership"
+)
+
+// CustomerAction is the next step needed to verify a customer
+type CustomerAction string
+
+// CustomerNextAction is the next step needed to verify a customer along
+// with the link used to perform it
+type CustomerNextAction struct {
+	Action        CustomerAction
+	DocumentTypes []DocumentType
+	Link          Link
+}
+
+// NextAction returns the next step needed to verify the customer
+//
+// Steps are resolved from the customer's status and links in the order
+// dwolla expects them to be completed: verification retries first, then
+// KBA, documents, beneficial owners and finally ownership certification.
+// Unverified, receive-only, suspended and deactivated customers need no
+// action.
+func (c *Customer) NextAction() CustomerNextAction {
+	switch c.Status {
+	case CustomerStatusSuspended, CustomerStatusDeactivated:
+		return CustomerNextAction{Action: CustomerActionNone}
+	}
+
+	if c.Type == CustomerTypeUnverified || c.Type == CustomerTypeReceiveOnly {
+		return CustomerNextAction{Action: CustomerActionNone}
+	}
+
+	if link, ok := c.Links["retry-verification"]; ok {
+		return CustomerNextAction{Action: CustomerActionRetry, Link: link}
+	}
+
+	if link, ok := c.Links["kba"]; ok {
+		return CustomerNextAction{Action: CustomerActionAnswerKBA, Link: link}
+	}
+
+	if link, ok := c.Links["verify-controller-and-business-with-document"]; ok {
+		return CustomerNextAction{
+			Action:        CustomerActionUploadControllerAndBusinessDocument,
+			DocumentTypes: append(c.controllerDocumentTypes(), DocumentTypeOther),
+			Link:          link,
+		}
+	}
+
+	if link, ok := c.Links["verify-with-document"]; ok {
+		if c.Type == CustomerTypeBusiness {
+			return CustomerNextAction{
+				Action:        CustomerActionUploadControllerDocument,
+				DocumentTypes: c.controllerDocumentTypes(),
+				Link:          link,
+			}
+		}
+
+		return CustomerNextAction{
+			Action:        CustomerActionUploadPersonalDocument,
+			DocumentTypes: identityDocumentTypes(),
+			Link:          link,
+		}
+	}
+
+	if link, ok := c.Links["verify-business-with-document"]; ok {
+		return CustomerNextAction{
+			Action:        CustomerActionUploadBusinessDocument,
+			DocumentTypes: []DocumentType{DocumentTypeOther},
+			Link:          link,
+		}
+	}
+
+	if link, ok := c.Links["verify-beneficial-owners"]; ok {
+		return CustomerNextAction{Action: CustomerActionAddBeneficialOwners, Link: link}
+	}
+
+	if link, ok := c.Links["certify-beneficial-ownership"]; ok {
+		return CustomerNextAction{Action: CustomerActionCertifyOwnership, Link: link}
+	}
+
+	return CustomerNextAction{Action: CustomerActionNone}
+}
+
+// controllerDocumentTypes returns the document types accepted for the
+// business controller
+//
+// Controllers without a U.S. address can only be verified with a passport.
+func (c *Customer) controllerDocumentTypes() []DocumentType {
+	if c.Controller.Address.Country != "" && c.Controller.Address.Country != "US" {
+		return []DocumentType{DocumentTypePassport}
+	}
+
+	return identityDocumentTypes()
+}
+
+// identityDocumentTypes returns the document types accepted to verify a
+// person
+func identityDocumentTypes() []DocumentType {
+	return []DocumentType{DocumentTypePassport, DocumentTypeLicense, DocumentTypeIDCard}
+}
+
+// CustomerRetryRequest is a verification retry request
+//
+// Dwolla requires every personal field to be resubmitted on retry, with the
+// full 9-digit SSN. Business retries also require the business fields and,
+// unless the business is a sole proprietorship, the controller.
+type CustomerRetryRequest struct {
+	FirstName              string             `json:"firstName"`
+	LastName               string             `json:"lastName"`
+	Email                  string             `json:"email"`
+	IPAddress              string             `json:"ipAddress,omitempty"`
+	Type                   CustomerType       `json:"type"`
+	DateOfBirth            string             `json:"dateOfBirth,omitempty"`
+	SSN                    string             `json:"ssn,omitempty"`
+	Phone                  string             `json:"phone,omitempty"`
+	Address1               string             `json:"address1"`
+	Address2               string             `json:"address2,omitempty"`
+	City                   string             `json:"city"`
+	State                  string             `json:"state"`
+	PostalCode             string             `json:"postalCode"`
+	BusinessClassification string             `json:"businessClassification,omitempty"`
+	BusinessType           string             `json:"businessType,omitempty"`
+	BusinessName           string             `json:"businessName,omitempty"`
+	DoingBusinessAs        string             `json:"doingBusinessAs,omitempty"`
+	EIN                    string             `json:"ein,omitempty"`
+	Website                string             `json:"website,omitempty"`
+	Controller             *ControllerRequest `json:"controller,omitempty"`
+}
+
+// CustomerRetryResult is the outcome of a verification retry
+type CustomerRetryResult struct {
+	Customer *Customer
+	Status   CustomerStatus
+}
+
+// Verified returns true if the customer was verified by the retry
+func (r CustomerRetryResult) Verified() bool {
+	return r.Status == CustomerStatusVerified
+}
+
+// DocumentRequired returns true if the customer must now upload a document
+func (r CustomerRetryResult) DocumentRequired() bool {
+	return r.Status == CustomerStatusDocument
+}
+
+// Suspended returns true if the customer was suspended after the retry
+func (r CustomerRetryResult) Suspended() bool {
+	return r.Status == CustomerStatusSuspended
+}
+
+// Validate checks that the retry request contains every field dwolla
+// requires
+func (r *CustomerRetryRequest) Validate() error {
+	var errs validationErrors
+
+	required := map[string]string{
+		"/firstName":  r.FirstName,
+		"/lastName":   r.LastName,
+		"/email":      r.Email,
+		"/address1":   r.Address1,
+		"/city":       r.City,
+		"/state":      r.State,
+		"/postalCode": r.PostalCode,
+	}
+
+	switch r.Type {
+	case CustomerTypePersonal:
+		required["/dateOfBirth"] = r.DateOfBirth
+		required["/ssn"] = r.SSN
+	case CustomerTypeBusiness:
+		required["/businessName"] = r.BusinessName
+		required["/businessType"] = r.BusinessType
+		required["/businessClassification"] = r.BusinessClassification
+
+		if r.BusinessType == BusinessTypeSoleProprietorship {
+			required["/dateOfBirth"] = r.DateOfBirth
+			required["/ssn"] = r.SSN
+		} else {
+			required["/ein"] = r.EIN
+		}
+	default:
+		errs.add("Invalid", "/type", "Retry is only available for personal and business customers.")
+	}
+
+	requireFields(&errs, required)
+
+	if r.SSN != "" && !fullSSN(r.SSN) {
+		errs.add("InvalidFormat", "/ssn", "Full 9-digit SSN required.")
+	}
+
+	if r.Type == CustomerTypeBusiness && r.BusinessType != BusinessTypeSoleProprietorship {
+		if r.Controller == nil {
+			errs.add("Required", "/controller", "controller required.")
+		} else {
+			r.validateController(&errs)
+		}
+	}
+
+	return errs.err()
+}
+
+// validateController checks the controller fields required on retry
+func (r *CustomerRetryRequest) validateController(errs *validationErrors) {
+	controller := r.Controller
+
+	required := map[string]string{
+		"/controller/firstName":                   controller.FirstName,
+		"/controller/lastName":                    controller.LastName,
+		"/controller/title":                       controller.Title,
+		"/controller/dateOfBirth":                 controller.DateOfBirth,
+		"/controller/address/address1":            controller.Address.Address1,
+		"/controller/address/city":                controller.Address.City,
+		"/controller/address/country":             controller.Address.Country,
+		"/controller/address/stateProvinceRegion": controller.Address.StateProvinceRegion,
+	}
+
+	if controller.Address.Country == "US" || controller.Address.Country == "" {
+		required["/controller/ssn"] = controller.SSN
+	} else if controller.Passport == nil {
+		errs.add("Required", "/controller/passport", "controller passport required.")
+	}
+
+	requireFields(errs, required)
+
+	if controller.SSN != "" && !fullSSN(controller.SSN) {
+		errs.add("InvalidFormat", "/controller/ssn", "Full 9-digit SSN required.")
+	}
+}
+
+// Retry resubmits the customer's verification details
+//
+// The request is validated before it is sent. The customer is refreshed
+// with the response, and the result reports whether the customer moved to
+// verified, document or suspended.
+//
+// see: https://docsv2.dwolla.com/#retry-verification
+func (c *Customer) Retry(ctx context.Context, body *CustomerRetryRequest) (*CustomerRetryResult, error) {
+	if _, ok := c.Links["retry-verification"]; !ok {
+		return nil, errors.New("No retry verification resource link")
+	}
+
+	if body == nil {
+		return nil, errors.New("No retry request")
+	}
+
+	if err := c.client.validate(body); err != nil {
+		return nil, err
+	}
+
+	var customer Customer
+
+	if err := c.client.Post(ctx, c.Links["retry-verification"].Href, body, nil, &customer); err != nil {
+		return nil, err
+	}
+
+	customer.client = c.client
+	*c = customer
+
+	return &CustomerRetryResult{Customer: c, Status: c.Status}, nil
+}
+
+// fullSSN returns true if the ssn contains exactly 9 digits, ignoring
+// dashes
+func fullSSN(ssn string) bool {
+	digits := strings.Replace(ssn, "-", "", -1)
+
+	return len(digits) == 9 && allDigits(digits)
+}
+
+// sortedKeys returns the map's keys in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
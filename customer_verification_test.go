@@ -0,0 +1,138 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerNextAction(t *testing.T) {
+	href := "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"
+
+	tests := []struct {
+		customer      Customer
+		action        CustomerAction
+		documentTypes []DocumentType
+	}{
+		{Customer{Type: CustomerTypeUnverified, Status: CustomerStatusUnverified}, CustomerActionNone, nil},
+		{Customer{Type: CustomerTypeReceiveOnly, Status: CustomerStatusUnverified}, CustomerActionNone, nil},
+		{Customer{Type: CustomerTypePersonal, Status: CustomerStatusVerified}, CustomerActionNone, nil},
+		{Customer{Type: CustomerTypePersonal, Status: CustomerStatusSuspended, Resource: Resource{Links: Links{"verify-with-document": Link{Href: href}}}}, CustomerActionNone, nil},
+		{Customer{Type: CustomerTypePersonal, Status: CustomerStatusRetry, Resource: Resource{Links: Links{"retry-verification": Link{Href: href}}}}, CustomerActionRetry, nil},
+		{Customer{Type: CustomerTypePersonal, Status: CustomerStatusUnverified, Resource: Resource{Links: Links{"kba": Link{Href: href}}}}, CustomerActionAnswerKBA, nil},
+		{Customer{Type: CustomerTypePersonal, Status: CustomerStatusDocument, Resource: Resource{Links: Links{"verify-with-document": Link{Href: href}}}}, CustomerActionUploadPersonalDocument, identityDocumentTypes()},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusDocument, Resource: Resource{Links: Links{"verify-with-document": Link{Href: href}}}}, CustomerActionUploadControllerDocument, identityDocumentTypes()},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusDocument, Controller: Controller{Address: Address{Country: "CA"}}, Resource: Resource{Links: Links{"verify-with-document": Link{Href: href}}}}, CustomerActionUploadControllerDocument, []DocumentType{DocumentTypePassport}},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusDocument, Resource: Resource{Links: Links{"verify-business-with-document": Link{Href: href}}}}, CustomerActionUploadBusinessDocument, []DocumentType{DocumentTypeOther}},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusDocument, Resource: Resource{Links: Links{"verify-controller-and-business-with-document": Link{Href: href}}}}, CustomerActionUploadControllerAndBusinessDocument, []DocumentType{DocumentTypePassport, DocumentTypeLicense, DocumentTypeIDCard, DocumentTypeOther}},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusVerified, Resource: Resource{Links: Links{"verify-beneficial-owners": Link{Href: href}, "certify-beneficial-ownership": Link{Href: href}}}}, CustomerActionAddBeneficialOwners, nil},
+		{Customer{Type: CustomerTypeBusiness, Status: CustomerStatusVerified, Resource: Resource{Links: Links{"certify-beneficial-ownership": Link{Href: href}}}}, CustomerActionCertifyOwnership, nil},
+	}
+
+	for _, test := range tests {
+		res := test.customer.NextAction()
+
+		assert.Equal(t, res.Action, test.action)
+		assert.Equal(t, res.DocumentTypes, test.documentTypes)
+
+		if test.action != CustomerActionNone {
+			assert.Equal(t, res.Link.Href, href)
+		}
+	}
+}
+
+func TestCustomerRetryRequestValidate(t *testing.T) {
+	req := newTestCustomerRetryRequest()
+	assert.Nil(t, req.Validate())
+
+	req.SSN = "6789"
+	err := req.Validate()
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Equal(t, err.(ValidationError).Embedded["errors"][0].Path, "/ssn")
+
+	req = &CustomerRetryRequest{Type: CustomerTypeBusiness, BusinessType: "llc"}
+	err = req.Validate()
+
+	assert.Error(t, err)
+
+	paths := []string{}
+	for _, e := range err.(ValidationError).Embedded["errors"] {
+		paths = append(paths, e.Path)
+	}
+
+	assert.Contains(t, paths, "/ein")
+	assert.Contains(t, paths, "/controller")
+	assert.NotContains(t, paths, "/ssn")
+
+	req.Controller = &ControllerRequest{Address: Address{Country: "CA"}}
+	err = req.Validate()
+
+	paths = []string{}
+	for _, e := range err.(ValidationError).Embedded["errors"] {
+		paths = append(paths, e.Path)
+	}
+
+	assert.Contains(t, paths, "/controller/passport")
+	assert.NotContains(t, paths, "/controller/ssn")
+
+	req = &CustomerRetryRequest{Type: CustomerTypeUnverified}
+	assert.Error(t, req.Validate())
+}
+
+func TestCustomerRetry(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer-verified.json"))
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"retry-verification": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}}, Status: CustomerStatusRetry}
+	res, err := customer.Retry(ctx, newTestCustomerRetryRequest())
+
+	assert.Nil(t, err)
+	assert.True(t, res.Verified())
+	assert.False(t, res.DocumentRequired())
+	assert.False(t, res.Suspended())
+	assert.Equal(t, customer.Status, CustomerStatusVerified)
+	assert.False(t, customer.RetryVerification())
+}
+
+func TestCustomerRetryError(t *testing.T) {
+	c := newMockClient(400, filepath.Join("testdata", "validation-error.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.Retry(ctx, newTestCustomerRetryRequest())
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"retry-verification": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	res, err = customer.Retry(ctx, &CustomerRetryRequest{Type: CustomerTypePersonal})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	res, err = customer.Retry(ctx, newTestCustomerRetryRequest())
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestCustomerRetryNilRequest(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer-verified.json"))
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"retry-verification": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}}}
+	res, err := customer.Retry(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestCustomerRetryDisableValidation(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer-verified.json"))
+	c.DisableValidation = true
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"retry-verification": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}}}
+	res, err := customer.Retry(ctx, &CustomerRetryRequest{Type: CustomerTypePersonal})
+
+	assert.Nil(t, err)
+	assert.True(t, res.Verified())
+}
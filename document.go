@@ -7,9 +7,27 @@ import (
 )
 
 const (
+	// DocumentBusinessDocNotSupported is when the business document type is
+	// not accepted
+	DocumentBusinessDocNotSupported DocumentFailureReason = "BusinessDocNotSupported"
+	// DocumentBusinessNameMismatch is when the business name on the document
+	// does not match
+	DocumentBusinessNameMismatch DocumentFailureReason = "BusinessNameMismatch"
+	// DocumentBusinessTypeMismatch is when the business type on the document
+	// does not match
+	DocumentBusinessTypeMismatch DocumentFailureReason = "BusinessTypeMismatch"
+	// DocumentScanDobMismatch is when the scanned date of birth does not
+	// match
+	DocumentScanDobMismatch DocumentFailureReason = "ScanDobMismatch"
+	// DocumentScanDuplicate is when the scanned document was already uploaded
+	DocumentScanDuplicate DocumentFailureReason = "ScanDuplicate"
+	// DocumentScanIDExpired is when the scanned I.D. has expired
+	DocumentScanIDExpired DocumentFailureReason = "ScanIdExpired"
 	// DocumentScanIDTypeNotSupported is when the scanned I.D. type is not
 	// supported
 	DocumentScanIDTypeNotSupported DocumentFailureReason = "ScanIdTypeNotSupported"
+	// DocumentScanIDUnrecognized is when the scanned I.D. is not recognized
+	DocumentScanIDUnrecognized DocumentFailureReason = "ScanIdUnrecognized"
 	// DocumentNameMismatch is when the scanned document name does not match
 	DocumentScanNameMismatch DocumentFailureReason = "ScanNameMismatch"
 	// DocumentScanNotReadable is when the scanned document is not readable
@@ -46,6 +64,7 @@ const (
 //
 // see: https://docsv2.dwolla.com/#documents
 type DocumentService interface {
+	ListAll(context.Context, string) ([]CustomerDocument, error)
 	Retrieve(context.Context, string) (*Document, error)
 }
 
@@ -62,6 +81,14 @@ type Document struct {
 	Type          DocumentType          `json:"type"`
 	Created       string                `json:"created"`
 	FailureReason DocumentFailureReason `json:"failureReason"`
+	// AllFailureReasons lists every reason the document was rejected
+	AllFailureReasons []DocumentFailure `json:"allFailureReasons,omitempty"`
+}
+
+// DocumentFailure is one reason a document was rejected
+type DocumentFailure struct {
+	Reason      DocumentFailureReason `json:"reason"`
+	Description string                `json:"description"`
 }
 
 // DocumentFailureReason is the reason document verification failed
@@ -80,12 +107,26 @@ type DocumentStatus string
 type DocumentType string
 
 // DocumentRequest is a verification document request
+//
+// Call Normalize before uploading to fit large photos to dwolla's size limit
+// and strip their metadata.
 type DocumentRequest struct {
 	Type     DocumentType
 	FileName string
 	File     io.Reader
 }
 
+// ListAll returns the documents uploaded for the customer matching the id
+// and for each of its beneficial owners
+func (d *DocumentServiceOp) ListAll(ctx context.Context, customerID string) ([]CustomerDocument, error) {
+	customer, err := d.client.Customer.Retrieve(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return customer.ListAllDocuments(ctx)
+}
+
 // Retrieve retrieves a document matching the id
 func (d *DocumentServiceOp) Retrieve(ctx context.Context, id string) (*Document, error) {
 	var document Document
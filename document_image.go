@@ -0,0 +1,543 @@
+package dwolla
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxDocumentSize is the largest document dwolla accepts, in bytes
+	MaxDocumentSize = 10 * 1024 * 1024
+	// DefaultDocumentMinDimension is the smallest the longest side of an
+	// image is scaled down to, so that it stays readable for verification
+	DefaultDocumentMinDimension = 1200
+	// DefaultDocumentQuality is the first JPEG quality tried when an image
+	// is recompressed
+	DefaultDocumentQuality = 90
+	// DefaultDocumentMinQuality is the lowest JPEG quality tried before an
+	// image is scaled down
+	DefaultDocumentMinQuality = 60
+	// MaxDocumentPixels is the largest image, in pixels, that is decoded
+	MaxDocumentPixels = 50 * 1000 * 1000
+)
+
+// ErrDocumentTooLarge is returned when a document cannot be made to fit
+// the size limit
+var ErrDocumentTooLarge = errors.New("document is too large")
+
+// ErrDocumentTooManyPixels is returned when a document image's dimensions
+// exceed MaxDocumentPixels
+var ErrDocumentTooManyPixels = errors.New("document image has too many pixels")
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// DocumentImageOptions configures document image normalization
+//
+// Zero values use MaxDocumentSize, no dimension limit,
+// DefaultDocumentMinDimension, DefaultDocumentQuality and
+// DefaultDocumentMinQuality.
+type DocumentImageOptions struct {
+	MaxSize      int
+	MaxDimension int
+	MinDimension int
+	Quality      int
+	MinQuality   int
+}
+
+// withDefaults returns a copy of the options with defaults applied
+func (o *DocumentImageOptions) withDefaults() DocumentImageOptions {
+	var opts DocumentImageOptions
+
+	if o != nil {
+		opts = *o
+	}
+
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = MaxDocumentSize
+	}
+
+	if opts.MinDimension <= 0 {
+		opts.MinDimension = DefaultDocumentMinDimension
+	}
+
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = DefaultDocumentQuality
+	}
+
+	if opts.MinQuality <= 0 {
+		opts.MinQuality = DefaultDocumentMinQuality
+	}
+
+	if opts.MinQuality > opts.Quality {
+		opts.MinQuality = opts.Quality
+	}
+
+	return opts
+}
+
+// DocumentImageReport describes what Normalize changed in a document
+//
+// Format is empty for documents that are not JPEG or PNG images, which are
+// passed through unchanged.
+type DocumentImageReport struct {
+	OriginalFormat   string
+	Format           string
+	OriginalSize     int
+	Size             int
+	OriginalWidth    int
+	OriginalHeight   int
+	Width            int
+	Height           int
+	Quality          int
+	MetadataStripped bool
+	Reencoded        bool
+	Resized          bool
+	Rotated          bool
+}
+
+// Changed returns true if the document was modified
+func (r *DocumentImageReport) Changed() bool {
+	return r.MetadataStripped || r.Reencoded || r.Resized || r.Rotated
+}
+
+// Normalize prepares a JPEG or PNG document for upload
+//
+// EXIF, XMP, IPTC and text metadata are removed. Images that fit the size
+// limit are otherwise left as they are; larger images are recompressed as
+// JPEG at decreasing quality and then scaled down until they fit, never
+// below the minimum dimension. JPEG images with an EXIF orientation are
+// rotated upright before their metadata is removed. Other documents, such
+// as PDFs, are passed through unchanged.
+//
+// The request's File, and FileName when the format changes, are replaced.
+// ErrDocumentTooLarge is returned if the document cannot be made to fit, and
+// ErrDocumentTooManyPixels if the image is larger than MaxDocumentPixels,
+// which is checked before it is decoded.
+func (r *DocumentRequest) Normalize(opts *DocumentImageOptions) (*DocumentImageReport, error) {
+	if r.File == nil {
+		return nil, errors.New("No document file")
+	}
+
+	o := opts.withDefaults()
+
+	data, err := ioutil.ReadAll(r.File)
+	if err != nil {
+		return nil, err
+	}
+
+	r.File = bytes.NewReader(data)
+
+	report := &DocumentImageReport{OriginalSize: len(data), Size: len(data)}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		if len(data) > o.MaxSize {
+			return nil, ErrDocumentTooLarge
+		}
+
+		return report, nil
+	}
+
+	if int64(config.Width)*int64(config.Height) > MaxDocumentPixels {
+		return nil, ErrDocumentTooManyPixels
+	}
+
+	report.OriginalFormat = format
+	report.Format = format
+	report.OriginalWidth, report.OriginalHeight = config.Width, config.Height
+	report.Width, report.Height = config.Width, config.Height
+
+	var (
+		stripped    []byte
+		ok          bool
+		orientation = 1
+	)
+
+	if format == "jpeg" {
+		stripped, orientation, ok = stripJPEGMetadata(data)
+	} else {
+		stripped, ok = stripPNGMetadata(data)
+	}
+
+	longest := config.Width
+	if config.Height > longest {
+		longest = config.Height
+	}
+
+	if ok && orientation == 1 && len(stripped) <= o.MaxSize && (o.MaxDimension <= 0 || longest <= o.MaxDimension) {
+		report.MetadataStripped = len(stripped) != len(data)
+		report.Size = len(stripped)
+		r.File = bytes.NewReader(stripped)
+
+		return report, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rgba := orientImage(toRGBA(img), orientation)
+	report.Rotated = orientation != 1
+
+	out, err := encodeDocumentImage(rgba, format, o, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Format != format && r.FileName != "" {
+		r.FileName = strings.TrimSuffix(r.FileName, filepath.Ext(r.FileName)) + ".jpg"
+	}
+
+	report.MetadataStripped = true
+	report.Reencoded = true
+	report.Size = len(out)
+	r.File = bytes.NewReader(out)
+
+	return report, nil
+}
+
+// encodeDocumentImage encodes the image so it fits the size limit
+//
+// PNG images are first tried as PNG at each scale, then as JPEG from the
+// starting quality down to the minimum quality. When nothing fits the image
+// is scaled down by a quarter and tried again.
+func encodeDocumentImage(img *image.RGBA, format string, o DocumentImageOptions, report *DocumentImageReport) ([]byte, error) {
+	bounds := img.Bounds()
+
+	longest := bounds.Dx()
+	if bounds.Dy() > longest {
+		longest = bounds.Dy()
+	}
+
+	target := longest
+	if o.MaxDimension > 0 && target > o.MaxDimension {
+		target = o.MaxDimension
+	}
+
+	for {
+		scaled := img
+		if target < longest {
+			scaled = scaleImage(img, target)
+			report.Resized = true
+		}
+
+		report.Width, report.Height = scaled.Bounds().Dx(), scaled.Bounds().Dy()
+
+		if format == "png" {
+			var buf bytes.Buffer
+
+			encoder := png.Encoder{CompressionLevel: png.BestCompression}
+			if err := encoder.Encode(&buf, scaled); err != nil {
+				return nil, err
+			}
+
+			if buf.Len() <= o.MaxSize {
+				report.Format = "png"
+				return buf.Bytes(), nil
+			}
+		}
+
+		flat := flattenImage(scaled)
+
+		for quality := o.Quality; quality >= o.MinQuality; quality -= 10 {
+			var buf bytes.Buffer
+
+			if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, err
+			}
+
+			if buf.Len() <= o.MaxSize {
+				report.Format = "jpeg"
+				report.Quality = quality
+				return buf.Bytes(), nil
+			}
+		}
+
+		if target <= o.MinDimension {
+			return nil, ErrDocumentTooLarge
+		}
+
+		target = target * 3 / 4
+		if target < o.MinDimension {
+			target = o.MinDimension
+		}
+	}
+}
+
+// toRGBA converts the image to RGBA with its origin at zero
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	return rgba
+}
+
+// flattenImage draws the image over a white background, so transparent
+// areas do not turn black when encoded as JPEG
+func flattenImage(img *image.RGBA) *image.RGBA {
+	if img.Opaque() {
+		return img
+	}
+
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, image.Point{}, draw.Over)
+
+	return flat
+}
+
+// scaleImage scales the image down so its longest side is the given size,
+// averaging the source pixels covered by each destination pixel
+func scaleImage(src *image.RGBA, longest int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := longest, h*longest/w
+	if h > w {
+		dw, dh = w*longest/h, longest
+	}
+
+	if dw < 1 {
+		dw = 1
+	}
+
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var sum [4]int
+
+			for sy := y0; sy < y1; sy++ {
+				offset := src.PixOffset(x0, sy)
+
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+
+					offset += 4
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			offset := dst.PixOffset(x, y)
+
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// orientImage applies an EXIF orientation so the image is upright
+func orientImage(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// stripJPEGMetadata removes the APP1 (EXIF and XMP), APP13 (IPTC) and
+// comment segments from a JPEG without re-encoding it
+//
+// The EXIF orientation is returned, or 1 if there is none. ok is false if
+// the JPEG could not be parsed, in which case data is returned unchanged.
+func stripJPEGMetadata(data []byte) (stripped []byte, orientation int, ok bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data, 1, false
+	}
+
+	out := []byte{0xFF, 0xD8}
+	orientation = 1
+
+	for i := 2; i+2 <= len(data); {
+		if data[i] != 0xFF {
+			return data, 1, false
+		}
+
+		marker := data[i+1]
+
+		switch {
+		case marker == 0xFF:
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			return append(out, data[i:]...), orientation, true
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return data, 1, false
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+
+		if length < 2 || end > len(data) {
+			return data, 1, false
+		}
+
+		switch marker {
+		case 0xE1:
+			if o := exifOrientation(data[i+4 : end]); o != 1 {
+				orientation = o
+			}
+		case 0xED, 0xFE:
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	return data, 1, false
+}
+
+// exifOrientation returns the orientation tag from an APP1 EXIF segment,
+// or 1 if there is none
+func exifOrientation(segment []byte) int {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 1
+	}
+
+	tiff := segment[6:]
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+
+		if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+			return value
+		}
+
+		return 1
+	}
+
+	return 1
+}
+
+// stripPNGMetadata removes the EXIF, text and time chunks from a PNG
+// without re-encoding it
+//
+// ok is false if the PNG could not be parsed, in which case data is returned
+// unchanged.
+func stripPNGMetadata(data []byte) (stripped []byte, ok bool) {
+	if len(data) < len(pngSignature) || string(data[:len(pngSignature)]) != pngSignature {
+		return data, false
+	}
+
+	out := append([]byte(nil), data[:len(pngSignature)]...)
+
+	for i := len(pngSignature); i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+
+		if length < 0 || end > len(data) {
+			return data, false
+		}
+
+		chunk := string(data[i+4 : i+8])
+
+		switch chunk {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		if chunk == "IEND" {
+			return out, true
+		}
+
+		i = end
+	}
+
+	return data, false
+}
@@ -0,0 +1,231 @@
+package dwolla
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestNoiseImage returns an image of random pixels, which compresses
+// poorly
+func newTestNoiseImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	r := rand.New(rand.NewSource(1))
+	r.Read(img.Pix)
+
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xFF
+	}
+
+	return img
+}
+
+// newTestEXIFJPEG returns a JPEG with an APP1 EXIF segment holding the
+// orientation
+func newTestEXIFJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+
+	assert.Nil(t, jpeg.Encode(&buf, img, nil))
+
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1}
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := buf.Bytes()
+
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestDocumentRequestNormalizeUnchanged(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.Nil(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+
+	original := append([]byte(nil), buf.Bytes()...)
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.png", File: &buf}
+	report, err := req.Normalize(nil)
+
+	assert.Nil(t, err)
+	assert.False(t, report.Changed())
+	assert.Equal(t, report.Format, "png")
+	assert.Equal(t, report.Width, 40)
+	assert.Equal(t, req.FileName, "license.png")
+
+	data, _ := ioutil.ReadAll(req.File)
+
+	assert.Equal(t, data, original)
+}
+
+func TestDocumentRequestNormalizeStripsEXIF(t *testing.T) {
+	data := newTestEXIFJPEG(t, image.NewGray(image.Rect(0, 0, 40, 20)), 1)
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.jpg", File: bytes.NewReader(data)}
+	report, err := req.Normalize(nil)
+
+	assert.Nil(t, err)
+	assert.True(t, report.MetadataStripped)
+	assert.False(t, report.Reencoded)
+	assert.True(t, report.Size < report.OriginalSize)
+
+	out, _ := ioutil.ReadAll(req.File)
+
+	assert.NotContains(t, string(out), "Exif")
+
+	_, err = jpeg.Decode(bytes.NewReader(out))
+
+	assert.Nil(t, err)
+}
+
+func TestDocumentRequestNormalizeRotates(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 40, 20))
+	data := newTestEXIFJPEG(t, img, 6)
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.jpg", File: bytes.NewReader(data)}
+	report, err := req.Normalize(nil)
+
+	assert.Nil(t, err)
+	assert.True(t, report.Rotated)
+	assert.True(t, report.Reencoded)
+	assert.Equal(t, report.Width, 20)
+	assert.Equal(t, report.Height, 40)
+
+	out, _ := ioutil.ReadAll(req.File)
+
+	assert.NotContains(t, string(out), "Exif")
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(out))
+
+	assert.Nil(t, err)
+	assert.Equal(t, config.Width, 20)
+	assert.Equal(t, config.Height, 40)
+}
+
+func TestDocumentRequestNormalizeFitsLimit(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.Nil(t, png.Encode(&buf, newTestNoiseImage(400, 300)))
+
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.png", File: &buf}
+	report, err := req.Normalize(&DocumentImageOptions{MaxSize: 20 * 1024, MinDimension: 100})
+
+	assert.Nil(t, err)
+	assert.True(t, report.Reencoded)
+	assert.True(t, report.Resized)
+	assert.Equal(t, report.OriginalFormat, "png")
+	assert.Equal(t, report.Format, "jpeg")
+	assert.True(t, report.Size <= 20*1024)
+	assert.True(t, report.Width < 400)
+	assert.True(t, report.Height < 300)
+	assert.Equal(t, req.FileName, "license.jpg")
+
+	out, _ := ioutil.ReadAll(req.File)
+
+	assert.Len(t, out, report.Size)
+}
+
+func TestDocumentRequestNormalizeMaxDimension(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.Nil(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 300))))
+
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.png", File: &buf}
+	report, err := req.Normalize(&DocumentImageOptions{MaxDimension: 200, MinDimension: 100})
+
+	assert.Nil(t, err)
+	assert.True(t, report.Resized)
+	assert.Equal(t, report.Format, "png")
+	assert.Equal(t, report.Width, 200)
+	assert.Equal(t, report.Height, 150)
+	assert.Equal(t, req.FileName, "license.png")
+}
+
+func TestDocumentRequestNormalizeTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.Nil(t, png.Encode(&buf, newTestNoiseImage(400, 300)))
+
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.png", File: &buf}
+	report, err := req.Normalize(&DocumentImageOptions{MaxSize: 1024, MinDimension: 300})
+
+	assert.Equal(t, err, ErrDocumentTooLarge)
+	assert.Nil(t, report)
+}
+
+func TestDocumentRequestNormalizeTooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+
+	assert.Nil(t, png.Encode(&buf, newTestNoiseImage(1, 1)))
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 10000)
+	binary.BigEndian.PutUint32(data[20:], 10000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	req := &DocumentRequest{Type: DocumentTypeLicense, FileName: "license.png", File: bytes.NewReader(data)}
+	report, err := req.Normalize(nil)
+
+	assert.Equal(t, err, ErrDocumentTooManyPixels)
+	assert.Nil(t, report)
+}
+
+func TestDocumentRequestNormalizeOther(t *testing.T) {
+	pdf := "%PDF-1.4\n%%EOF\n"
+
+	req := &DocumentRequest{Type: DocumentTypeOther, FileName: "ein.pdf", File: strings.NewReader(pdf)}
+	report, err := req.Normalize(nil)
+
+	assert.Nil(t, err)
+	assert.False(t, report.Changed())
+	assert.Empty(t, report.Format)
+	assert.Equal(t, report.Size, len(pdf))
+
+	data, _ := ioutil.ReadAll(req.File)
+
+	assert.Equal(t, string(data), pdf)
+
+	req = &DocumentRequest{Type: DocumentTypeOther, FileName: "ein.pdf", File: strings.NewReader(pdf)}
+	_, err = req.Normalize(&DocumentImageOptions{MaxSize: 4})
+
+	assert.Equal(t, err, ErrDocumentTooLarge)
+
+	_, err = (&DocumentRequest{}).Normalize(nil)
+
+	assert.Error(t, err)
+}
+
+func TestOrientImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 0xFF, A: 0xFF})
+	src.Set(1, 0, color.RGBA{B: 0xFF, A: 0xFF})
+
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+
+	assert.Equal(t, orientImage(src, 1), src)
+	assert.Equal(t, orientImage(src, 2).RGBAAt(1, 0), red)
+	assert.Equal(t, orientImage(src, 3).RGBAAt(1, 0), red)
+	assert.Equal(t, orientImage(src, 4).RGBAAt(0, 0), red)
+
+	// Orientations 5 to 8 swap width and height.
+	assert.Equal(t, orientImage(src, 6).Bounds().Dx(), 1)
+	assert.Equal(t, orientImage(src, 6).RGBAAt(0, 0), red)
+	assert.Equal(t, orientImage(src, 8).RGBAAt(0, 1), red)
+	assert.Equal(t, orientImage(src, 5).RGBAAt(0, 0), red)
+	assert.Equal(t, orientImage(src, 7).RGBAAt(0, 1), red)
+}
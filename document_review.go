@@ -0,0 +1,388 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultDocumentReviewInterval is the polling interval used by
+// WaitForReview when none is given
+const DefaultDocumentReviewInterval = 30 * time.Second
+
+// DocumentRemediation is the guidance shown to a user after a document was
+// rejected
+type DocumentRemediation struct {
+	Reason        DocumentFailureReason
+	Message       string
+	DocumentTypes []DocumentType
+}
+
+// documentRemediations maps failure reasons to the action the user should
+// take and the document types that can be uploaded in response
+var documentRemediations = map[DocumentFailureReason]DocumentRemediation{
+	DocumentScanDobMismatch: {
+		Message:       "The date of birth on the document does not match the one on file. Upload a document showing the same date of birth, or correct the date of birth and retry verification.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanDuplicate: {
+		Message:       "This document has already been uploaded. Upload a different document.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanFailedOther: {
+		Message:       "The document could not be verified. Upload a different identity document.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanIDExpired: {
+		Message:       "The document has expired. Upload an identity document that has not expired.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanIDTypeNotSupported: {
+		Message:       "This type of identification is not accepted. Upload a passport, a state-issued driver's license or a U.S. government-issued photo I.D. card.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanIDUnrecognized: {
+		Message:       "The document was not recognized as a valid identity document. Upload a passport, a state-issued driver's license or a U.S. government-issued photo I.D. card.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanNameMismatch: {
+		Message:       "The name on the document does not match the name on file. Upload a document showing the name exactly as it was entered.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanNotReadable: {
+		Message:       "The document could not be read. Upload a clear, well-lit color image showing all four corners of the document.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentScanNotUploaded: {
+		Message:       "The image did not contain a document. Upload an image of the front of the document.",
+		DocumentTypes: identityDocumentTypes(),
+	},
+	DocumentBusinessDocNotSupported: {
+		Message:       "This document is not accepted for business verification. Upload an EIN letter or another document showing the business name and EIN.",
+		DocumentTypes: []DocumentType{DocumentTypeOther},
+	},
+	DocumentBusinessNameMismatch: {
+		Message:       "The business name on the document does not match the name on file. Upload a document showing the legal business name as it was entered.",
+		DocumentTypes: []DocumentType{DocumentTypeOther},
+	},
+	DocumentBusinessTypeMismatch: {
+		Message:       "The business type on the document does not match the business type on file. Upload a document for the business type that was entered.",
+		DocumentTypes: []DocumentType{DocumentTypeOther},
+	},
+	DocumentFailedOther: {
+		Message:       "The document could not be verified. Upload a different document or contact support.",
+		DocumentTypes: []DocumentType{DocumentTypePassport, DocumentTypeLicense, DocumentTypeIDCard, DocumentTypeOther},
+	},
+}
+
+// Remediation returns the guidance for the failure reason
+//
+// Unknown reasons fall back to the guidance for DocumentFailedOther.
+func (r DocumentFailureReason) Remediation() DocumentRemediation {
+	remediation, ok := documentRemediations[r]
+	if !ok {
+		remediation = documentRemediations[DocumentFailedOther]
+	}
+
+	remediation.Reason = r
+	remediation.DocumentTypes = append([]DocumentType(nil), remediation.DocumentTypes...)
+
+	return remediation
+}
+
+// Accepted returns true if the document was reviewed and accepted
+func (d *Document) Accepted() bool {
+	return d.Status == DocumentStatusReviewed && d.FailureReason == ""
+}
+
+// Rejected returns true if the document was reviewed and rejected
+func (d *Document) Rejected() bool {
+	return d.Status == DocumentStatusReviewed && d.FailureReason != ""
+}
+
+// Remediations returns the guidance for each reason the document was
+// rejected, or nil if it was not rejected
+func (d *Document) Remediations() []DocumentRemediation {
+	if !d.Rejected() {
+		return nil
+	}
+
+	var remediations []DocumentRemediation
+
+	seen := map[DocumentFailureReason]bool{}
+
+	for _, failure := range append([]DocumentFailure{{Reason: d.FailureReason}}, d.AllFailureReasons...) {
+		if failure.Reason == "" || seen[failure.Reason] {
+			continue
+		}
+
+		seen[failure.Reason] = true
+		remediations = append(remediations, failure.Reason.Remediation())
+	}
+
+	return remediations
+}
+
+// Refresh retrieves the document again and updates it in place
+func (d *Document) Refresh(ctx context.Context) error {
+	if _, ok := d.Links["self"]; !ok {
+		return errors.New("No self resource link")
+	}
+
+	var document Document
+
+	if err := d.client.Get(ctx, d.Links["self"].Href, nil, nil, &document); err != nil {
+		return err
+	}
+
+	document.client = d.client
+	*d = document
+
+	return nil
+}
+
+// WaitForReview blocks until the document is no longer pending, refreshing
+// it every interval
+//
+// It returns the context's error if the context is done first.
+func (d *Document) WaitForReview(ctx context.Context, interval time.Duration) error {
+	if _, ok := d.Links["self"]; !ok {
+		return errors.New("No self resource link")
+	}
+
+	if interval <= 0 {
+		interval = DefaultDocumentReviewInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for d.Status == DocumentStatusPending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := d.Refresh(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WaitForReviewEvent blocks until the document is no longer pending,
+// refreshing it when a document approved or failed event for it is received
+//
+// Events for other resources are ignored. An error is returned if the
+// events channel is closed while the document is still pending.
+func (d *Document) WaitForReviewEvent(ctx context.Context, events <-chan *Event) error {
+	if _, ok := d.Links["self"]; !ok {
+		return errors.New("No self resource link")
+	}
+
+	for d.Status == DocumentStatusPending {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return errors.New("Event channel closed before the document was reviewed")
+			}
+
+			if !d.reviewedBy(event) {
+				continue
+			}
+
+			if err := d.Refresh(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reviewedBy returns true if the event reports the document's review
+func (d *Document) reviewedBy(event *Event) bool {
+	if event == nil {
+		return false
+	}
+
+	switch event.Topic {
+	case EventTopicCustomerVerificationDocumentApproved,
+		EventTopicCustomerVerificationDocumentFailed,
+		EventTopicCustomerBeneficialOwnerVerificationDocumentApproved,
+		EventTopicCustomerBeneficialOwnerVerificationDocumentFailed:
+	default:
+		return false
+	}
+
+	if d.ID != "" && strings.EqualFold(event.ResourceID, d.ID) {
+		return true
+	}
+
+	link, ok := event.Links["resource"]
+
+	return ok && strings.EqualFold(link.Href, d.Links["self"].Href)
+}
+
+// CustomerDocument is a document uploaded for a customer or one of its
+// beneficial owners
+//
+// BeneficialOwner is nil for the customer's own documents.
+type CustomerDocument struct {
+	Document        *Document
+	BeneficialOwner *BeneficialOwner
+}
+
+// ListAllDocuments returns the documents uploaded for the customer and for
+// each of its beneficial owners
+//
+// Beneficial owners are only listed when the customer has a beneficial
+// owners link.
+func (c *Customer) ListAllDocuments(ctx context.Context) ([]CustomerDocument, error) {
+	documents, err := c.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []CustomerDocument
+
+	for i := range documents.Embedded["documents"] {
+		all = append(all, CustomerDocument{Document: &documents.Embedded["documents"][i]})
+	}
+
+	if _, ok := c.Links["beneficial-owners"]; !ok {
+		return all, nil
+	}
+
+	owners, err := c.ListBeneficialOwners(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range owners.Embedded["beneficial-owners"] {
+		owner := &owners.Embedded["beneficial-owners"][i]
+
+		documents, err := owner.ListDocuments(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range documents.Embedded["documents"] {
+			all = append(all, CustomerDocument{Document: &documents.Embedded["documents"][j], BeneficialOwner: owner})
+		}
+	}
+
+	return all, nil
+}
+
+// DocumentUploadNotAllowedError is returned when a document cannot be
+// re-uploaded in the resource's current state
+type DocumentUploadNotAllowedError struct {
+	Type          DocumentType
+	DocumentTypes []DocumentType
+}
+
+// Error implements the error interface
+func (e *DocumentUploadNotAllowedError) Error() string {
+	if len(e.DocumentTypes) == 0 {
+		return "Document upload is not allowed"
+	}
+
+	types := make([]string, len(e.DocumentTypes))
+
+	for i, t := range e.DocumentTypes {
+		types[i] = string(t)
+	}
+
+	return fmt.Sprintf("Document type %s is not allowed, expected one of: %s", e.Type, strings.Join(types, ", "))
+}
+
+// ReuploadDocument uploads a replacement document for the customer
+//
+// The customer is refreshed first, and the upload is only sent when its
+// current links request a document of the given type. Otherwise a
+// *DocumentUploadNotAllowedError is returned.
+func (c *Customer) ReuploadDocument(ctx context.Context, body *DocumentRequest) (*Document, error) {
+	if _, ok := c.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	var customer Customer
+
+	if err := c.client.Get(ctx, c.Links["self"].Href, nil, nil, &customer); err != nil {
+		return nil, err
+	}
+
+	customer.client = c.client
+	*c = customer
+
+	next := c.NextAction()
+
+	switch next.Action {
+	case CustomerActionUploadPersonalDocument,
+		CustomerActionUploadControllerDocument,
+		CustomerActionUploadBusinessDocument,
+		CustomerActionUploadControllerAndBusinessDocument:
+	default:
+		return nil, &DocumentUploadNotAllowedError{Type: body.Type}
+	}
+
+	if !hasDocumentType(next.DocumentTypes, body.Type) {
+		return nil, &DocumentUploadNotAllowedError{Type: body.Type, DocumentTypes: next.DocumentTypes}
+	}
+
+	return c.CreateDocument(ctx, body)
+}
+
+// ReuploadDocument uploads a replacement document for the beneficial owner
+//
+// The beneficial owner is refreshed first, and the upload is only sent when
+// it still has a verify with document link. Owners without a U.S. address
+// can only upload a passport. Otherwise a *DocumentUploadNotAllowedError is
+// returned.
+func (b *BeneficialOwner) ReuploadDocument(ctx context.Context, body *DocumentRequest) (*Document, error) {
+	if _, ok := b.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	var owner BeneficialOwner
+
+	if err := b.client.Get(ctx, b.Links["self"].Href, nil, nil, &owner); err != nil {
+		return nil, err
+	}
+
+	owner.client = b.client
+	*b = owner
+
+	if _, ok := b.Links["verify-with-document"]; !ok {
+		return nil, &DocumentUploadNotAllowedError{Type: body.Type}
+	}
+
+	types := identityDocumentTypes()
+
+	if b.Address.Country != "" && b.Address.Country != "US" {
+		types = []DocumentType{DocumentTypePassport}
+	}
+
+	if !hasDocumentType(types, body.Type) {
+		return nil, &DocumentUploadNotAllowedError{Type: body.Type, DocumentTypes: types}
+	}
+
+	return b.CreateDocument(ctx, body)
+}
+
+// hasDocumentType returns true if the document type is in types
+func hasDocumentType(types []DocumentType, documentType DocumentType) bool {
+	for _, t := range types {
+		if t == documentType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+package dwolla
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDocument = "https://api-sandbox.dwolla.com/documents/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc"
+
+func TestDocumentFailureReasonRemediation(t *testing.T) {
+	remediation := DocumentScanNotReadable.Remediation()
+
+	assert.Equal(t, remediation.Reason, DocumentScanNotReadable)
+	assert.Contains(t, remediation.Message, "four corners")
+	assert.Equal(t, remediation.DocumentTypes, identityDocumentTypes())
+
+	remediation = DocumentBusinessNameMismatch.Remediation()
+
+	assert.Equal(t, remediation.DocumentTypes, []DocumentType{DocumentTypeOther})
+
+	remediation = DocumentFailureReason("SomethingNew").Remediation()
+
+	assert.Equal(t, remediation.Reason, DocumentFailureReason("SomethingNew"))
+	assert.Equal(t, remediation.Message, DocumentFailedOther.Remediation().Message)
+}
+
+func TestDocumentRemediations(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "document-rejected.json"))
+	document, err := c.Document.Retrieve(ctx, "56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc")
+
+	assert.Nil(t, err)
+	assert.True(t, document.Rejected())
+	assert.False(t, document.Accepted())
+
+	remediations := document.Remediations()
+
+	assert.Len(t, remediations, 2)
+	assert.Equal(t, remediations[0].Reason, DocumentScanNameMismatch)
+	assert.Equal(t, remediations[1].Reason, DocumentScanDobMismatch)
+
+	assert.Nil(t, (&Document{Status: DocumentStatusPending}).Remediations())
+	assert.True(t, (&Document{Status: DocumentStatusReviewed}).Accepted())
+}
+
+func TestDocumentWaitForReview(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /documents/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc": {200, filepath.Join("testdata", "document-rejected.json")},
+	})
+
+	document := &Document{Resource: Resource{client: c, Links: Links{"self": Link{Href: testDocument}}}, Status: DocumentStatusPending}
+
+	assert.Nil(t, document.WaitForReview(ctx, time.Millisecond))
+	assert.Equal(t, document.Status, DocumentStatusReviewed)
+	assert.Equal(t, document.FailureReason, DocumentScanNameMismatch)
+	assert.Len(t, mc.requestLog(), 1)
+
+	// Reviewed documents return immediately.
+	assert.Nil(t, document.WaitForReview(ctx, time.Millisecond))
+	assert.Len(t, mc.requestLog(), 1)
+}
+
+func TestDocumentWaitForReviewError(t *testing.T) {
+	assert.Error(t, (&Document{Status: DocumentStatusPending}).WaitForReview(ctx, time.Millisecond))
+
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /documents/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc": {200, filepath.Join("testdata", "document.json")},
+	})
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	document := &Document{Resource: Resource{client: c, Links: Links{"self": Link{Href: testDocument}}}, Status: DocumentStatusPending}
+
+	assert.Equal(t, document.WaitForReview(timeout, time.Millisecond), context.DeadlineExceeded)
+
+	document.client = newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	assert.Error(t, document.WaitForReview(ctx, time.Millisecond))
+}
+
+func TestDocumentWaitForReviewEvent(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /documents/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc": {200, filepath.Join("testdata", "document-rejected.json")},
+	})
+
+	events := make(chan *Event, 3)
+	events <- &Event{Topic: EventTopicCustomerTransferCreated, ResourceID: "56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc"}
+	events <- &Event{Topic: EventTopicCustomerVerificationDocumentFailed, ResourceID: "11fe0bab-39bd-42ee-bb39-275afcc050d0"}
+	events <- &Event{
+		Resource: Resource{Links: Links{"resource": Link{Href: testDocument}}},
+		Topic:    EventTopicCustomerVerificationDocumentFailed,
+	}
+
+	document := &Document{Resource: Resource{client: c, Links: Links{"self": Link{Href: testDocument}}}, Status: DocumentStatusPending}
+
+	assert.Nil(t, document.WaitForReviewEvent(ctx, events))
+	assert.True(t, document.Rejected())
+	assert.Len(t, mc.requestLog(), 1)
+	assert.Len(t, events, 0)
+}
+
+func TestDocumentWaitForReviewEventError(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "document.json"))
+
+	events := make(chan *Event)
+	close(events)
+
+	assert.Error(t, (&Document{Status: DocumentStatusPending}).WaitForReviewEvent(ctx, events))
+
+	document := &Document{Resource: Resource{client: c, Links: Links{"self": Link{Href: testDocument}}}, Status: DocumentStatusPending}
+
+	assert.Error(t, document.WaitForReviewEvent(ctx, events))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.Equal(t, document.WaitForReviewEvent(cancelled, make(chan *Event)), context.Canceled)
+}
+
+func TestCustomerListAllDocuments(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath:                                               {200, filepath.Join("testdata", "customer-business-document.json")},
+		"GET " + testCustomerPath + "/documents":                                {200, filepath.Join("testdata", "documents.json")},
+		"GET " + testCustomerPath + "/beneficial-owners":                        {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"GET /beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98/documents": {200, filepath.Join("testdata", "documents.json")},
+		"GET /beneficial-owners/caa81a5f-ec1e-4559-8b32-d90655bfd03c/documents": {200, filepath.Join("testdata", "documents.json")},
+	})
+
+	documents, err := c.Document.ListAll(ctx, "56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc")
+
+	assert.Nil(t, err)
+	assert.Len(t, documents, 6)
+	assert.Nil(t, documents[0].BeneficialOwner)
+	assert.Nil(t, documents[1].BeneficialOwner)
+	assert.Equal(t, documents[2].BeneficialOwner.ID, "55469604-40ab-44b6-962f-de2c0837ba98")
+	assert.Equal(t, documents[5].BeneficialOwner.ID, "caa81a5f-ec1e-4559-8b32-d90655bfd03c")
+	assert.Equal(t, documents[5].Document.ID, "11fe0bab-39bd-42ee-bb39-275afcc050d0")
+}
+
+func TestCustomerListAllDocumentsError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	documents, err := (&Customer{Resource: Resource{client: c}}).ListAllDocuments(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, documents)
+
+	documents, err = c.Document.ListAll(ctx, "56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc")
+
+	assert.Error(t, err)
+	assert.Nil(t, documents)
+}
+
+func TestCustomerReuploadDocument(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath:                 {200, filepath.Join("testdata", "customer-business-document.json")},
+		"POST " + testCustomerPath + "/documents": {201, filepath.Join("testdata", "document.json")},
+	})
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com" + testCustomerPath}}}}
+	document, err := customer.ReuploadDocument(ctx, &DocumentRequest{Type: DocumentTypeOther, FileName: "ein.png", File: strings.NewReader("ein")})
+
+	assert.Nil(t, document)
+	assert.IsType(t, &DocumentUploadNotAllowedError{}, err)
+	assert.Equal(t, err.(*DocumentUploadNotAllowedError).DocumentTypes, identityDocumentTypes())
+	assert.Equal(t, customer.Status, CustomerStatusDocument)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/documents"), 0)
+
+	document, err = customer.ReuploadDocument(ctx, newTestDocumentRequest())
+
+	assert.Nil(t, err)
+	assert.Equal(t, document.Status, DocumentStatusPending)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/documents"), 1)
+}
+
+func TestCustomerReuploadDocumentNotAllowed(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F": {200, filepath.Join("testdata", "customer-verified.json")},
+	})
+
+	customer := &Customer{Resource: Resource{client: c}}
+	_, err := customer.ReuploadDocument(ctx, newTestDocumentRequest())
+
+	assert.Error(t, err)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	document, err := customer.ReuploadDocument(ctx, newTestDocumentRequest())
+
+	assert.Nil(t, document)
+	assert.IsType(t, &DocumentUploadNotAllowedError{}, err)
+	assert.Equal(t, err.Error(), "Document upload is not allowed")
+	assert.Len(t, mc.requestLog(), 1)
+}
+
+func TestBeneficialOwnerReuploadDocument(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98":            {200, filepath.Join("testdata", "beneficial-owner-document.json")},
+		"POST /beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98/documents": {201, filepath.Join("testdata", "document.json")},
+		"GET /beneficial-owners/07d59716-ef22-4fe6-98e8-f3190233dfb8":            {200, filepath.Join("testdata", "beneficial-owner.json")},
+	})
+
+	owner := &BeneficialOwner{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98"}}}}
+	document, err := owner.ReuploadDocument(ctx, newTestDocumentRequest())
+
+	assert.Nil(t, err)
+	assert.NotNil(t, document)
+	assert.Equal(t, countMockRequests(mc, "POST", "/beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98/documents"), 1)
+
+	document, err = owner.ReuploadDocument(ctx, &DocumentRequest{Type: DocumentTypeOther, FileName: "ein.png", File: strings.NewReader("ein")})
+
+	assert.Nil(t, document)
+	assert.IsType(t, &DocumentUploadNotAllowedError{}, err)
+	assert.Contains(t, err.Error(), "Document type other is not allowed")
+
+	owner = &BeneficialOwner{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/beneficial-owners/07d59716-ef22-4fe6-98e8-f3190233dfb8"}}}}
+	document, err = owner.ReuploadDocument(ctx, newTestDocumentRequest())
+
+	assert.Nil(t, document)
+	assert.IsType(t, &DocumentUploadNotAllowedError{}, err)
+}
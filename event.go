@@ -2,10 +2,332 @@ package dwolla
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 )
 
+const (
+	// EventResourceTypeAccount is when the event refers to an account
+	EventResourceTypeAccount EventResourceType = "account"
+	// EventResourceTypeBeneficialOwner is when the event refers to a
+	// beneficial owner
+	EventResourceTypeBeneficialOwner EventResourceType = "beneficial-owner"
+	// EventResourceTypeCustomer is when the event refers to a customer
+	EventResourceTypeCustomer EventResourceType = "customer"
+	// EventResourceTypeDocument is when the event refers to a document
+	EventResourceTypeDocument EventResourceType = "document"
+	// EventResourceTypeFundingSource is when the event refers to a funding
+	// source
+	EventResourceTypeFundingSource EventResourceType = "funding-source"
+	// EventResourceTypeKBA is when the event refers to a kba session
+	EventResourceTypeKBA EventResourceType = "kba"
+	// EventResourceTypeLabel is when the event refers to a label
+	EventResourceTypeLabel EventResourceType = "label"
+	// EventResourceTypeLabelLedgerEntry is when the event refers to a label
+	// ledger entry
+	EventResourceTypeLabelLedgerEntry EventResourceType = "ledger-entry"
+	// EventResourceTypeMassPayment is when the event refers to a mass payment
+	EventResourceTypeMassPayment EventResourceType = "mass-payment"
+	// EventResourceTypeTransfer is when the event refers to a transfer
+	EventResourceTypeTransfer EventResourceType = "transfer"
+	// EventResourceTypeUnknown is when the event topic is not recognized
+	EventResourceTypeUnknown EventResourceType = ""
+)
+
+const (
+	// EventTopicAccountSuspended is when the account is suspended
+	EventTopicAccountSuspended EventTopic = "account_suspended"
+	// EventTopicAccountActivated is when the account is activated
+	EventTopicAccountActivated EventTopic = "account_activated"
+	// EventTopicFundingSourceAdded is when an account funding source is added
+	EventTopicFundingSourceAdded EventTopic = "funding_source_added"
+	// EventTopicFundingSourceRemoved is when an account funding source is
+	// removed
+	EventTopicFundingSourceRemoved EventTopic = "funding_source_removed"
+	// EventTopicFundingSourceVerified is when an account funding source is
+	// verified
+	EventTopicFundingSourceVerified EventTopic = "funding_source_verified"
+	// EventTopicFundingSourceUnverified is when an account funding source is
+	// unverified
+	EventTopicFundingSourceUnverified EventTopic = "funding_source_unverified"
+	// EventTopicFundingSourceNegative is when an account balance goes
+	// negative
+	EventTopicFundingSourceNegative EventTopic = "funding_source_negative"
+	// EventTopicFundingSourceUpdated is when an account funding source is
+	// updated
+	EventTopicFundingSourceUpdated EventTopic = "funding_source_updated"
+	// EventTopicMicroDepositsAdded is when account micro deposits are
+	// initiated
+	EventTopicMicroDepositsAdded EventTopic = "microdeposits_added"
+	// EventTopicMicroDepositsFailed is when account micro deposits fail
+	EventTopicMicroDepositsFailed EventTopic = "microdeposits_failed"
+	// EventTopicMicroDepositsCompleted is when account micro deposits complete
+	EventTopicMicroDepositsCompleted EventTopic = "microdeposits_completed"
+	// EventTopicMicroDepositsMaxAttempts is when account micro deposit
+	// verification attempts are exhausted
+	EventTopicMicroDepositsMaxAttempts EventTopic = "microdeposits_maxattempts"
+	// EventTopicBankTransferCreated is when an account bank transfer is
+	// created
+	EventTopicBankTransferCreated EventTopic = "bank_transfer_created"
+	// EventTopicBankTransferCreationFailed is when an account bank transfer
+	// could not be created
+	EventTopicBankTransferCreationFailed EventTopic = "bank_transfer_creation_failed"
+	// EventTopicBankTransferCancelled is when an account bank transfer is
+	// cancelled
+	EventTopicBankTransferCancelled EventTopic = "bank_transfer_cancelled"
+	// EventTopicBankTransferFailed is when an account bank transfer fails
+	EventTopicBankTransferFailed EventTopic = "bank_transfer_failed"
+	// EventTopicBankTransferCompleted is when an account bank transfer
+	// completes
+	EventTopicBankTransferCompleted EventTopic = "bank_transfer_completed"
+	// EventTopicTransferCreated is when an account transfer is created
+	EventTopicTransferCreated EventTopic = "transfer_created"
+	// EventTopicTransferCancelled is when an account transfer is cancelled
+	EventTopicTransferCancelled EventTopic = "transfer_cancelled"
+	// EventTopicTransferFailed is when an account transfer fails
+	EventTopicTransferFailed EventTopic = "transfer_failed"
+	// EventTopicTransferReclaimed is when an account transfer is reclaimed
+	EventTopicTransferReclaimed EventTopic = "transfer_reclaimed"
+	// EventTopicTransferCompleted is when an account transfer completes
+	EventTopicTransferCompleted EventTopic = "transfer_completed"
+	// EventTopicMassPaymentCreated is when an account mass payment is created
+	EventTopicMassPaymentCreated EventTopic = "mass_payment_created"
+	// EventTopicMassPaymentCompleted is when an account mass payment completes
+	EventTopicMassPaymentCompleted EventTopic = "mass_payment_completed"
+	// EventTopicMassPaymentCancelled is when an account mass payment is
+	// cancelled
+	EventTopicMassPaymentCancelled EventTopic = "mass_payment_cancelled"
+)
+
+const (
+	// EventTopicCustomerCreated is when a customer is created
+	EventTopicCustomerCreated EventTopic = "customer_created"
+	// EventTopicCustomerKBAVerificationNeeded is when a customer needs to
+	// complete kba
+	EventTopicCustomerKBAVerificationNeeded EventTopic = "customer_kba_verification_needed"
+	// EventTopicCustomerKBAVerificationFailed is when a customer fails kba
+	EventTopicCustomerKBAVerificationFailed EventTopic = "customer_kba_verification_failed"
+	// EventTopicCustomerKBAVerificationPassed is when a customer passes kba
+	EventTopicCustomerKBAVerificationPassed EventTopic = "customer_kba_verification_passed"
+	// EventTopicCustomerVerificationDocumentNeeded is when a customer needs a
+	// verification document
+	EventTopicCustomerVerificationDocumentNeeded EventTopic = "customer_verification_document_needed"
+	// EventTopicCustomerVerificationDocumentUploaded is when a customer
+	// verification document is uploaded
+	EventTopicCustomerVerificationDocumentUploaded EventTopic = "customer_verification_document_uploaded"
+	// EventTopicCustomerVerificationDocumentFailed is when a customer
+	// verification document is rejected
+	EventTopicCustomerVerificationDocumentFailed EventTopic = "customer_verification_document_failed"
+	// EventTopicCustomerVerificationDocumentApproved is when a customer
+	// verification document is approved
+	EventTopicCustomerVerificationDocumentApproved EventTopic = "customer_verification_document_approved"
+	// EventTopicCustomerReverificationNeeded is when a customer needs to
+	// retry verification
+	EventTopicCustomerReverificationNeeded EventTopic = "customer_reverification_needed"
+	// EventTopicCustomerVerified is when a customer is verified
+	EventTopicCustomerVerified EventTopic = "customer_verified"
+	// EventTopicCustomerSuspended is when a customer is suspended
+	EventTopicCustomerSuspended EventTopic = "customer_suspended"
+	// EventTopicCustomerActivated is when a customer is activated
+	EventTopicCustomerActivated EventTopic = "customer_activated"
+	// EventTopicCustomerDeactivated is when a customer is deactivated
+	EventTopicCustomerDeactivated EventTopic = "customer_deactivated"
+)
+
+const (
+	// EventTopicCustomerBeneficialOwnerCreated is when a beneficial owner is
+	// created
+	EventTopicCustomerBeneficialOwnerCreated EventTopic = "customer_beneficial_owner_created"
+	// EventTopicCustomerBeneficialOwnerRemoved is when a beneficial owner is
+	// removed
+	EventTopicCustomerBeneficialOwnerRemoved EventTopic = "customer_beneficial_owner_removed"
+	// EventTopicCustomerBeneficialOwnerVerificationDocumentNeeded is when a
+	// beneficial owner needs a verification document
+	EventTopicCustomerBeneficialOwnerVerificationDocumentNeeded EventTopic = "customer_beneficial_owner_verification_document_needed"
+	// EventTopicCustomerBeneficialOwnerVerificationDocumentUploaded is when a
+	// beneficial owner verification document is uploaded
+	EventTopicCustomerBeneficialOwnerVerificationDocumentUploaded EventTopic = "customer_beneficial_owner_verification_document_uploaded"
+	// EventTopicCustomerBeneficialOwnerVerificationDocumentFailed is when a
+	// beneficial owner verification document is rejected
+	EventTopicCustomerBeneficialOwnerVerificationDocumentFailed EventTopic = "customer_beneficial_owner_verification_document_failed"
+	// EventTopicCustomerBeneficialOwnerVerificationDocumentApproved is when a
+	// beneficial owner verification document is approved
+	EventTopicCustomerBeneficialOwnerVerificationDocumentApproved EventTopic = "customer_beneficial_owner_verification_document_approved"
+	// EventTopicCustomerBeneficialOwnerReverificationNeeded is when a
+	// beneficial owner needs to retry verification
+	EventTopicCustomerBeneficialOwnerReverificationNeeded EventTopic = "customer_beneficial_owner_reverification_needed"
+	// EventTopicCustomerBeneficialOwnerVerified is when a beneficial owner is
+	// verified
+	EventTopicCustomerBeneficialOwnerVerified EventTopic = "customer_beneficial_owner_verified"
+)
+
+const (
+	// EventTopicCustomerFundingSourceAdded is when a customer funding source
+	// is added
+	EventTopicCustomerFundingSourceAdded EventTopic = "customer_funding_source_added"
+	// EventTopicCustomerFundingSourceRemoved is when a customer funding source
+	// is removed
+	EventTopicCustomerFundingSourceRemoved EventTopic = "customer_funding_source_removed"
+	// EventTopicCustomerFundingSourceVerified is when a customer funding
+	// source is verified
+	EventTopicCustomerFundingSourceVerified EventTopic = "customer_funding_source_verified"
+	// EventTopicCustomerFundingSourceUnverified is when a customer funding
+	// source is unverified
+	EventTopicCustomerFundingSourceUnverified EventTopic = "customer_funding_source_unverified"
+	// EventTopicCustomerFundingSourceNegative is when a customer balance goes
+	// negative
+	EventTopicCustomerFundingSourceNegative EventTopic = "customer_funding_source_negative"
+	// EventTopicCustomerFundingSourceUpdated is when a customer funding
+	// source is updated
+	EventTopicCustomerFundingSourceUpdated EventTopic = "customer_funding_source_updated"
+	// EventTopicCustomerMicroDepositsAdded is when customer micro deposits
+	// are initiated
+	EventTopicCustomerMicroDepositsAdded EventTopic = "customer_microdeposits_added"
+	// EventTopicCustomerMicroDepositsFailed is when customer micro deposits
+	// fail
+	EventTopicCustomerMicroDepositsFailed EventTopic = "customer_microdeposits_failed"
+	// EventTopicCustomerMicroDepositsCompleted is when customer micro
+	// deposits complete
+	EventTopicCustomerMicroDepositsCompleted EventTopic = "customer_microdeposits_completed"
+	// EventTopicCustomerMicroDepositsMaxAttempts is when customer micro
+	// deposit verification attempts are exhausted
+	EventTopicCustomerMicroDepositsMaxAttempts EventTopic = "customer_microdeposits_maxattempts"
+	// EventTopicCustomerBalanceInquiryCompleted is when a customer balance
+	// inquiry completes
+	EventTopicCustomerBalanceInquiryCompleted EventTopic = "customer_balance_inquiry_completed"
+)
+
+const (
+	// EventTopicCustomerBankTransferCreated is when a customer bank transfer
+	// is created
+	EventTopicCustomerBankTransferCreated EventTopic = "customer_bank_transfer_created"
+	// EventTopicCustomerBankTransferCreationFailed is when a customer bank
+	// transfer could not be created
+	EventTopicCustomerBankTransferCreationFailed EventTopic = "customer_bank_transfer_creation_failed"
+	// EventTopicCustomerBankTransferCancelled is when a customer bank
+	// transfer is cancelled
+	EventTopicCustomerBankTransferCancelled EventTopic = "customer_bank_transfer_cancelled"
+	// EventTopicCustomerBankTransferFailed is when a customer bank transfer
+	// fails
+	EventTopicCustomerBankTransferFailed EventTopic = "customer_bank_transfer_failed"
+	// EventTopicCustomerBankTransferCompleted is when a customer bank
+	// transfer completes
+	EventTopicCustomerBankTransferCompleted EventTopic = "customer_bank_transfer_completed"
+	// EventTopicCustomerTransferCreated is when a customer transfer is created
+	EventTopicCustomerTransferCreated EventTopic = "customer_transfer_created"
+	// EventTopicCustomerTransferCancelled is when a customer transfer is
+	// cancelled
+	EventTopicCustomerTransferCancelled EventTopic = "customer_transfer_cancelled"
+	// EventTopicCustomerTransferFailed is when a customer transfer fails
+	EventTopicCustomerTransferFailed EventTopic = "customer_transfer_failed"
+	// EventTopicCustomerTransferCompleted is when a customer transfer
+	// completes
+	EventTopicCustomerTransferCompleted EventTopic = "customer_transfer_completed"
+	// EventTopicCustomerMassPaymentCreated is when a customer mass payment is
+	// created
+	EventTopicCustomerMassPaymentCreated EventTopic = "customer_mass_payment_created"
+	// EventTopicCustomerMassPaymentCompleted is when a customer mass payment
+	// completes
+	EventTopicCustomerMassPaymentCompleted EventTopic = "customer_mass_payment_completed"
+	// EventTopicCustomerMassPaymentCancelled is when a customer mass payment
+	// is cancelled
+	EventTopicCustomerMassPaymentCancelled EventTopic = "customer_mass_payment_cancelled"
+)
+
+const (
+	// EventTopicCustomerLabelCreated is when a customer label is created
+	EventTopicCustomerLabelCreated EventTopic = "customer_label_created"
+	// EventTopicCustomerLabelLedgerEntryCreated is when a label ledger entry
+	// is created
+	EventTopicCustomerLabelLedgerEntryCreated EventTopic = "customer_label_ledger_entry_created"
+	// EventTopicCustomerLabelRemoved is when a customer label is removed
+	EventTopicCustomerLabelRemoved EventTopic = "customer_label_removed"
+)
+
+// eventTopicResourceTypes maps each event topic to the type of resource
+// found in the event's resource link
+var eventTopicResourceTypes = map[EventTopic]EventResourceType{
+	EventTopicAccountSuspended:           EventResourceTypeAccount,
+	EventTopicAccountActivated:           EventResourceTypeAccount,
+	EventTopicFundingSourceAdded:         EventResourceTypeFundingSource,
+	EventTopicFundingSourceRemoved:       EventResourceTypeFundingSource,
+	EventTopicFundingSourceVerified:      EventResourceTypeFundingSource,
+	EventTopicFundingSourceUnverified:    EventResourceTypeFundingSource,
+	EventTopicFundingSourceNegative:      EventResourceTypeFundingSource,
+	EventTopicFundingSourceUpdated:       EventResourceTypeFundingSource,
+	EventTopicMicroDepositsAdded:         EventResourceTypeFundingSource,
+	EventTopicMicroDepositsFailed:        EventResourceTypeFundingSource,
+	EventTopicMicroDepositsCompleted:     EventResourceTypeFundingSource,
+	EventTopicMicroDepositsMaxAttempts:   EventResourceTypeFundingSource,
+	EventTopicBankTransferCreated:        EventResourceTypeTransfer,
+	EventTopicBankTransferCreationFailed: EventResourceTypeTransfer,
+	EventTopicBankTransferCancelled:      EventResourceTypeTransfer,
+	EventTopicBankTransferFailed:         EventResourceTypeTransfer,
+	EventTopicBankTransferCompleted:      EventResourceTypeTransfer,
+	EventTopicTransferCreated:            EventResourceTypeTransfer,
+	EventTopicTransferCancelled:          EventResourceTypeTransfer,
+	EventTopicTransferFailed:             EventResourceTypeTransfer,
+	EventTopicTransferReclaimed:          EventResourceTypeTransfer,
+	EventTopicTransferCompleted:          EventResourceTypeTransfer,
+	EventTopicMassPaymentCreated:         EventResourceTypeMassPayment,
+	EventTopicMassPaymentCompleted:       EventResourceTypeMassPayment,
+	EventTopicMassPaymentCancelled:       EventResourceTypeMassPayment,
+
+	EventTopicCustomerCreated:                      EventResourceTypeCustomer,
+	EventTopicCustomerKBAVerificationNeeded:        EventResourceTypeKBA,
+	EventTopicCustomerKBAVerificationFailed:        EventResourceTypeKBA,
+	EventTopicCustomerKBAVerificationPassed:        EventResourceTypeKBA,
+	EventTopicCustomerVerificationDocumentNeeded:   EventResourceTypeCustomer,
+	EventTopicCustomerVerificationDocumentUploaded: EventResourceTypeDocument,
+	EventTopicCustomerVerificationDocumentFailed:   EventResourceTypeDocument,
+	EventTopicCustomerVerificationDocumentApproved: EventResourceTypeDocument,
+	EventTopicCustomerReverificationNeeded:         EventResourceTypeCustomer,
+	EventTopicCustomerVerified:                     EventResourceTypeCustomer,
+	EventTopicCustomerSuspended:                    EventResourceTypeCustomer,
+	EventTopicCustomerActivated:                    EventResourceTypeCustomer,
+	EventTopicCustomerDeactivated:                  EventResourceTypeCustomer,
+
+	EventTopicCustomerBeneficialOwnerCreated:                      EventResourceTypeBeneficialOwner,
+	EventTopicCustomerBeneficialOwnerRemoved:                      EventResourceTypeBeneficialOwner,
+	EventTopicCustomerBeneficialOwnerVerificationDocumentNeeded:   EventResourceTypeBeneficialOwner,
+	EventTopicCustomerBeneficialOwnerVerificationDocumentUploaded: EventResourceTypeDocument,
+	EventTopicCustomerBeneficialOwnerVerificationDocumentFailed:   EventResourceTypeDocument,
+	EventTopicCustomerBeneficialOwnerVerificationDocumentApproved: EventResourceTypeDocument,
+	EventTopicCustomerBeneficialOwnerReverificationNeeded:         EventResourceTypeBeneficialOwner,
+	EventTopicCustomerBeneficialOwnerVerified:                     EventResourceTypeBeneficialOwner,
+
+	EventTopicCustomerFundingSourceAdded:       EventResourceTypeFundingSource,
+	EventTopicCustomerFundingSourceRemoved:     EventResourceTypeFundingSource,
+	EventTopicCustomerFundingSourceVerified:    EventResourceTypeFundingSource,
+	EventTopicCustomerFundingSourceUnverified:  EventResourceTypeFundingSource,
+	EventTopicCustomerFundingSourceNegative:    EventResourceTypeFundingSource,
+	EventTopicCustomerFundingSourceUpdated:     EventResourceTypeFundingSource,
+	EventTopicCustomerMicroDepositsAdded:       EventResourceTypeFundingSource,
+	EventTopicCustomerMicroDepositsFailed:      EventResourceTypeFundingSource,
+	EventTopicCustomerMicroDepositsCompleted:   EventResourceTypeFundingSource,
+	EventTopicCustomerMicroDepositsMaxAttempts: EventResourceTypeFundingSource,
+	EventTopicCustomerBalanceInquiryCompleted:  EventResourceTypeFundingSource,
+
+	EventTopicCustomerBankTransferCreated:        EventResourceTypeTransfer,
+	EventTopicCustomerBankTransferCreationFailed: EventResourceTypeTransfer,
+	EventTopicCustomerBankTransferCancelled:      EventResourceTypeTransfer,
+	EventTopicCustomerBankTransferFailed:         EventResourceTypeTransfer,
+	EventTopicCustomerBankTransferCompleted:      EventResourceTypeTransfer,
+	EventTopicCustomerTransferCreated:            EventResourceTypeTransfer,
+	EventTopicCustomerTransferCancelled:          EventResourceTypeTransfer,
+	EventTopicCustomerTransferFailed:             EventResourceTypeTransfer,
+	EventTopicCustomerTransferCompleted:          EventResourceTypeTransfer,
+	EventTopicCustomerMassPaymentCreated:         EventResourceTypeMassPayment,
+	EventTopicCustomerMassPaymentCompleted:       EventResourceTypeMassPayment,
+	EventTopicCustomerMassPaymentCancelled:       EventResourceTypeMassPayment,
+
+	EventTopicCustomerLabelCreated:            EventResourceTypeLabel,
+	EventTopicCustomerLabelLedgerEntryCreated: EventResourceTypeLabelLedgerEntry,
+	EventTopicCustomerLabelRemoved:            EventResourceTypeLabel,
+}
+
 // EventService is the event service interface
 //
 // see: https://docsv2.dwolla.com/#events
@@ -19,9 +341,36 @@ type EventServiceOp struct {
 	client *Client
 }
 
+// EventResourceType is the type of resource an event topic refers to
+type EventResourceType string
+
 // EventTopic is an event topic
 type EventTopic string
 
+// EventTopics returns every known event topic
+func EventTopics() []EventTopic {
+	topics := make([]EventTopic, 0, len(eventTopicResourceTypes))
+
+	for topic := range eventTopicResourceTypes {
+		topics = append(topics, topic)
+	}
+
+	sort.Slice(topics, func(i, j int) bool { return topics[i] < topics[j] })
+
+	return topics
+}
+
+// Known returns true if the event topic is a documented dwolla topic
+func (t EventTopic) Known() bool {
+	_, ok := eventTopicResourceTypes[t]
+	return ok
+}
+
+// ResourceType returns the type of resource the event topic refers to
+func (t EventTopic) ResourceType() EventResourceType {
+	return eventTopicResourceTypes[t]
+}
+
 // Event is a dwolla event
 type Event struct {
 	Resource
@@ -29,6 +378,7 @@ type Event struct {
 	Created    string     `json:"created"`
 	Topic      EventTopic `json:"topic"`
 	ResourceID string     `json:"resourceId"`
+	Timestamp  string     `json:"timestamp,omitempty"`
 }
 
 // Events is a collection of dwolla events
@@ -71,3 +421,71 @@ func (e *EventServiceOp) Retrieve(ctx context.Context, id string) (*Event, error
 
 	return &event, nil
 }
+
+// RetrieveResource retrieves the resource the event refers to
+//
+// The returned value is an *Account, *Customer, *Transfer, *FundingSource,
+// *MassPayment, *BeneficialOwner, *Document, *KBA, *Label or
+// *LabelLedgerEntry depending on the event's topic. It is not named
+// Resource because that is the event's embedded Resource field.
+func (e *Event) RetrieveResource(ctx context.Context) (interface{}, error) {
+	var resource interface{}
+
+	if _, ok := e.Links["resource"]; !ok {
+		return nil, errors.New("No resource resource link")
+	}
+
+	switch e.Topic.ResourceType() {
+	case EventResourceTypeAccount:
+		resource = &Account{}
+	case EventResourceTypeCustomer:
+		resource = &Customer{}
+	case EventResourceTypeTransfer:
+		resource = &Transfer{}
+	case EventResourceTypeFundingSource:
+		resource = &FundingSource{}
+	case EventResourceTypeMassPayment:
+		resource = &MassPayment{}
+	case EventResourceTypeBeneficialOwner:
+		resource = &BeneficialOwner{}
+	case EventResourceTypeDocument:
+		resource = &Document{}
+	case EventResourceTypeKBA:
+		resource = &KBA{}
+	case EventResourceTypeLabel:
+		resource = &Label{}
+	case EventResourceTypeLabelLedgerEntry:
+		resource = &LabelLedgerEntry{}
+	default:
+		return nil, fmt.Errorf("Unsupported resource type for topic %s", e.Topic)
+	}
+
+	if err := e.client.Get(ctx, e.Links["resource"].Href, nil, nil, resource); err != nil {
+		return nil, err
+	}
+
+	switch r := resource.(type) {
+	case *Account:
+		r.client = e.client
+	case *Customer:
+		r.client = e.client
+	case *Transfer:
+		r.client = e.client
+	case *FundingSource:
+		r.client = e.client
+	case *MassPayment:
+		r.client = e.client
+	case *BeneficialOwner:
+		r.client = e.client
+	case *Document:
+		r.client = e.client
+	case *KBA:
+		r.client = e.client
+	case *Label:
+		r.client = e.client
+	case *LabelLedgerEntry:
+		r.client = e.client
+	}
+
+	return resource, nil
+}
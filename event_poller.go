@@ -0,0 +1,261 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultEventPollerInterval is the default time between event polls
+	DefaultEventPollerInterval = time.Minute
+	// DefaultEventPollerPageSize is the default number of events per page
+	DefaultEventPollerPageSize = 25
+)
+
+// ErrEventCheckpointNotFound is returned when the checkpointed event no
+// longer exists
+var ErrEventCheckpointNotFound = errors.New("event checkpoint not found")
+
+// EventHandler handles a single dwolla event
+type EventHandler func(context.Context, *Event) error
+
+// EventCheckpointStore persists the id of the last handled event
+type EventCheckpointStore interface {
+	Load(context.Context) (string, error)
+	Save(context.Context, string) error
+}
+
+// MemoryEventCheckpointStore is an in-memory event checkpoint store
+type MemoryEventCheckpointStore struct {
+	mu      sync.Mutex
+	eventID string
+}
+
+// Load returns the checkpointed event id
+func (m *MemoryEventCheckpointStore) Load(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.eventID, nil
+}
+
+// Save checkpoints the event id
+func (m *MemoryEventCheckpointStore) Save(ctx context.Context, eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.eventID = eventID
+
+	return nil
+}
+
+// FileEventCheckpointStore is an event checkpoint store backed by a file
+type FileEventCheckpointStore struct {
+	Path string
+}
+
+// Load returns the checkpointed event id, or an empty string if the file
+// does not exist
+func (f *FileEventCheckpointStore) Load(ctx context.Context) (string, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Save checkpoints the event id
+func (f *FileEventCheckpointStore) Save(ctx context.Context, eventID string) error {
+	return writeFileAtomic(f.Path, []byte(eventID))
+}
+
+// EventPoller replays dwolla events that have not yet been handled
+//
+// Events are walked from newest back to the checkpointed event, then handed
+// to the handler oldest-first. The checkpoint is saved after each event is
+// handled, so a failed run resumes from the last handled event. When no
+// checkpoint exists, every event dwolla still retains is replayed.
+//
+// The walk also stops at events created before the checkpoint, and events
+// repeated across pages are only handled once. If the checkpointed event
+// no longer exists, Poll returns ErrEventCheckpointNotFound rather than
+// replaying the whole history; save a new checkpoint to resume.
+//
+// Run keeps polling when a poll fails; OnError, when set, is called with
+// each failed poll's error.
+type EventPoller struct {
+	Client   *Client
+	Store    EventCheckpointStore
+	Handler  EventHandler
+	Interval time.Duration
+	PageSize int
+	OnError  func(error)
+}
+
+// NewEventPoller initializes a new event poller
+func NewEventPoller(client *Client, store EventCheckpointStore, handler EventHandler) *EventPoller {
+	return &EventPoller{
+		Client:   client,
+		Store:    store,
+		Handler:  handler,
+		Interval: DefaultEventPollerInterval,
+		PageSize: DefaultEventPollerPageSize,
+	}
+}
+
+// Poll handles every event newer than the checkpoint and returns the
+// number of events handled
+func (p *EventPoller) Poll(ctx context.Context) (int, error) {
+	if err := p.check(); err != nil {
+		return 0, err
+	}
+
+	checkpoint, err := p.Store.Load(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var since time.Time
+
+	if checkpoint != "" {
+		event, err := p.Client.Event.Retrieve(ctx, checkpoint)
+		if halError, ok := err.(HALError); ok && halError.Code == "NotFound" {
+			return 0, fmt.Errorf("%w: %s", ErrEventCheckpointNotFound, checkpoint)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		since, _ = time.Parse(time.RFC3339, event.Created)
+	}
+
+	pending, err := p.pending(ctx, checkpoint, since)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		event := pending[i]
+
+		if err := p.Handler(ctx, &event); err != nil {
+			return len(pending) - 1 - i, err
+		}
+
+		if err := p.Store.Save(ctx, event.ID); err != nil {
+			return len(pending) - i, err
+		}
+	}
+
+	return len(pending), nil
+}
+
+// Run polls for events until the context is cancelled
+//
+// A failed poll is reported to OnError and retried on the next tick. Run
+// only returns early if the poller is missing its client, store or handler.
+func (p *EventPoller) Run(ctx context.Context) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultEventPollerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Poll(ctx); err != nil && ctx.Err() == nil && p.OnError != nil {
+			p.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// check returns an error if the poller is missing its client, store or
+// handler
+func (p *EventPoller) check() error {
+	if p.Client == nil || p.Store == nil || p.Handler == nil {
+		return errors.New("Event poller requires a client, store and handler")
+	}
+
+	return nil
+}
+
+// pending returns events newer than the checkpoint, newest first
+//
+// Events are skipped if they were already seen on an earlier page, and the
+// walk ends at a page with no new events.
+func (p *EventPoller) pending(ctx context.Context, checkpoint string, since time.Time) ([]Event, error) {
+	var pending []Event
+
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultEventPollerPageSize
+	}
+
+	params := &EventListOptions{ListOptions{Limit: pageSize}}
+
+	events, err := p.Client.Event.List(ctx, params.Values())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+
+	for {
+		added := 0
+
+		for _, event := range events.Embedded["events"] {
+			if checkpoint != "" && event.ID == checkpoint {
+				return pending, nil
+			}
+
+			if created, err := time.Parse(time.RFC3339, event.Created); err == nil && created.Before(since) {
+				return pending, nil
+			}
+
+			if seen[event.ID] {
+				continue
+			}
+
+			seen[event.ID] = true
+			pending = append(pending, event)
+			added++
+		}
+
+		next, ok := events.Links["next"]
+		if !ok || added == 0 {
+			return pending, nil
+		}
+
+		events = &Events{}
+
+		if err := p.Client.Get(ctx, next.Href, nil, nil, events); err != nil {
+			return nil, err
+		}
+
+		for i := range events.Embedded["events"] {
+			events.Embedded["events"][i].client = p.Client
+		}
+	}
+}
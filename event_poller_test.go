@@ -0,0 +1,238 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventPollerPoll(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+		"GET /events/9f0167e0-dce6-4a1a-ad26-30015d6f1cc1": {200, filepath.Join("testdata", "event.json")},
+	})
+
+	store := &MemoryEventCheckpointStore{}
+	store.Save(ctx, "9f0167e0-dce6-4a1a-ad26-30015d6f1cc1")
+
+	var handled []string
+
+	poller := NewEventPoller(c, store, func(ctx context.Context, e *Event) error {
+		handled = append(handled, e.ID)
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 2)
+	assert.Equal(t, handled, []string{"f8e70f48-b7ff-47d0-9d3d-62a099363a76", "78e57644-56e4-4da2-b743-059479f2e80f"})
+
+	checkpoint, _ := store.Load(ctx)
+	assert.Equal(t, checkpoint, "78e57644-56e4-4da2-b743-059479f2e80f")
+}
+
+func TestEventPollerPollWithoutCheckpoint(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /events?limit=25":           {200, filepath.Join("testdata", "events.json")},
+		"GET /events?limit=25&offset=25": {200, filepath.Join("testdata", "events-empty.json")},
+	})
+
+	var handled []string
+
+	poller := NewEventPoller(c, &MemoryEventCheckpointStore{}, func(ctx context.Context, e *Event) error {
+		handled = append(handled, e.ID)
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 3)
+	assert.Equal(t, handled[0], "9f0167e0-dce6-4a1a-ad26-30015d6f1cc1")
+	assert.Len(t, mc.requestLog(), 2)
+}
+
+func TestEventPollerPollRepeatedEvents(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+	})
+
+	var handled []string
+
+	poller := NewEventPoller(c, &MemoryEventCheckpointStore{}, func(ctx context.Context, e *Event) error {
+		handled = append(handled, e.ID)
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 3)
+	assert.Len(t, handled, 3)
+	assert.Len(t, mc.requestLog(), 2)
+}
+
+func TestEventPollerPollCheckpointTime(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+		"GET /events/4e7c5f1a-0d2b-4a3e-9f61-2c8b7d5e3a90": {200, filepath.Join("testdata", "event-checkpoint.json")},
+	})
+
+	store := &MemoryEventCheckpointStore{}
+	store.Save(ctx, "4e7c5f1a-0d2b-4a3e-9f61-2c8b7d5e3a90")
+
+	var handled []string
+
+	poller := NewEventPoller(c, store, func(ctx context.Context, e *Event) error {
+		handled = append(handled, e.ID)
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 1)
+	assert.Equal(t, handled, []string{"78e57644-56e4-4da2-b743-059479f2e80f"})
+}
+
+func TestEventPollerPollCheckpointNotFound(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+		"GET /events/4e7c5f1a-0d2b-4a3e-9f61-2c8b7d5e3a90": {404, filepath.Join("testdata", "resource-not-found.json")},
+	})
+
+	store := &MemoryEventCheckpointStore{}
+	store.Save(ctx, "4e7c5f1a-0d2b-4a3e-9f61-2c8b7d5e3a90")
+
+	poller := NewEventPoller(c, store, func(ctx context.Context, e *Event) error {
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.True(t, errors.Is(err, ErrEventCheckpointNotFound))
+	assert.Equal(t, count, 0)
+	assert.Equal(t, countMockRequests(mc, "GET", "/events"), 0)
+}
+
+func TestEventPollerPollHandlerError(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+		"GET /events/9f0167e0-dce6-4a1a-ad26-30015d6f1cc1": {200, filepath.Join("testdata", "event.json")},
+	})
+
+	store := &MemoryEventCheckpointStore{}
+	store.Save(ctx, "9f0167e0-dce6-4a1a-ad26-30015d6f1cc1")
+
+	poller := NewEventPoller(c, store, func(ctx context.Context, e *Event) error {
+		if e.ID == "78e57644-56e4-4da2-b743-059479f2e80f" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, count, 1)
+
+	checkpoint, _ := store.Load(ctx)
+	assert.Equal(t, checkpoint, "f8e70f48-b7ff-47d0-9d3d-62a099363a76")
+}
+
+func TestEventPollerPollError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	poller := NewEventPoller(c, &MemoryEventCheckpointStore{}, func(ctx context.Context, e *Event) error {
+		return nil
+	})
+
+	count, err := poller.Poll(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, count, 0)
+
+	poller.Handler = nil
+	_, err = poller.Poll(ctx)
+
+	assert.Error(t, err)
+}
+
+func TestEventPollerRun(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /events": {200, filepath.Join("testdata", "events.json")},
+		"GET /events/9f0167e0-dce6-4a1a-ad26-30015d6f1cc1": {200, filepath.Join("testdata", "event.json")},
+	})
+
+	store := &MemoryEventCheckpointStore{}
+	store.Save(ctx, "9f0167e0-dce6-4a1a-ad26-30015d6f1cc1")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		handled []string
+		errs    []error
+		calls   int
+	)
+
+	poller := NewEventPoller(c, store, func(ctx context.Context, e *Event) error {
+		calls++
+
+		if calls == 1 {
+			return errors.New("handler failed")
+		}
+
+		handled = append(handled, e.ID)
+
+		if len(handled) == 2 {
+			cancel()
+		}
+
+		return nil
+	})
+	poller.Interval = 10 * time.Millisecond
+	poller.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+
+	err := poller.Run(runCtx)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "handler failed", errs[0].Error())
+	assert.Equal(t, []string{"f8e70f48-b7ff-47d0-9d3d-62a099363a76", "78e57644-56e4-4da2-b743-059479f2e80f"}, handled)
+
+	checkpoint, _ := store.Load(ctx)
+	assert.Equal(t, "78e57644-56e4-4da2-b743-059479f2e80f", checkpoint)
+}
+
+func TestEventPollerRunError(t *testing.T) {
+	poller := &EventPoller{}
+
+	assert.Error(t, poller.Run(ctx))
+}
+
+func TestFileEventCheckpointStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "dwolla")
+	defer os.RemoveAll(dir)
+
+	store := &FileEventCheckpointStore{Path: filepath.Join(dir, "checkpoint")}
+
+	id, err := store.Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "")
+
+	assert.Nil(t, store.Save(ctx, "78e57644-56e4-4da2-b743-059479f2e80f"))
+
+	id, err = store.Load(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "78e57644-56e4-4da2-b743-059479f2e80f")
+}
@@ -41,3 +41,70 @@ func TestEventServiceListError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, res)
 }
+
+func TestEventTopicResourceType(t *testing.T) {
+	assert.Equal(t, EventTopicCustomerCreated.ResourceType(), EventResourceTypeCustomer)
+	assert.Equal(t, EventTopicCustomerTransferCreated.ResourceType(), EventResourceTypeTransfer)
+	assert.Equal(t, EventTopicCustomerFundingSourceAdded.ResourceType(), EventResourceTypeFundingSource)
+	assert.Equal(t, EventTopicMassPaymentCompleted.ResourceType(), EventResourceTypeMassPayment)
+	assert.Equal(t, EventTopicCustomerLabelLedgerEntryCreated.ResourceType(), EventResourceTypeLabelLedgerEntry)
+	assert.Equal(t, EventTopic("foo_bar").ResourceType(), EventResourceTypeUnknown)
+
+	assert.True(t, EventTopicAccountActivated.Known())
+	assert.False(t, EventTopic("foo_bar").Known())
+
+	for _, topic := range EventTopics() {
+		assert.NotEqual(t, topic.ResourceType(), EventResourceTypeUnknown)
+	}
+}
+
+func TestEventRetrieveResource(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "transfer.json"))
+
+	event := &Event{
+		Resource: Resource{client: c, Links: Links{"resource": Link{Href: "https://api-sandbox.dwolla.com/transfers/4C8AD8B8-3D69-E511-80DB-0AA34A9B2388"}}},
+		Topic:    EventTopicCustomerTransferCreated,
+	}
+	res, err := event.RetrieveResource(ctx)
+
+	assert.Nil(t, err)
+	assert.IsType(t, &Transfer{}, res)
+	assert.Equal(t, res.(*Transfer).ID, "4C8AD8B8-3D69-E511-80DB-0AA34A9B2388")
+}
+
+func TestEventRetrieveResourceAccount(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "account.json"))
+
+	event := &Event{
+		Resource: Resource{client: c, Links: Links{"resource": Link{Href: "https://api-sandbox.dwolla.com/accounts/ca32853c-48fa-40be-ae75-77b37504581b"}}},
+		Topic:    EventTopicAccountActivated,
+	}
+	res, err := event.RetrieveResource(ctx)
+
+	assert.Nil(t, err)
+	assert.IsType(t, &Account{}, res)
+	assert.Equal(t, res.(*Account).ID, "ca32853c-48fa-40be-ae75-77b37504581b")
+}
+
+func TestEventRetrieveResourceError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	event := &Event{Resource: Resource{client: c}, Topic: EventTopicCustomerTransferCreated}
+	res, err := event.RetrieveResource(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	event.Links = Links{"resource": Link{Href: "https://api-sandbox.dwolla.com/statements/1"}}
+	event.Topic = EventTopic("statement_created")
+	res, err = event.RetrieveResource(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	event.Topic = EventTopicCustomerTransferCreated
+	res, err = event.RetrieveResource(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
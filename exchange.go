@@ -0,0 +1,308 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ExchangePartnerFinicity is the Finicity exchange partner
+	ExchangePartnerFinicity = "Finicity"
+	// ExchangePartnerFlinks is the Flinks exchange partner
+	ExchangePartnerFlinks = "Flinks"
+	// ExchangePartnerMX is the MX exchange partner
+	ExchangePartnerMX = "MX"
+	// ExchangePartnerPlaid is the Plaid exchange partner
+	ExchangePartnerPlaid = "Plaid"
+)
+
+const (
+	// ExchangePartnerStatusActive is when the exchange partner is active
+	ExchangePartnerStatusActive ExchangePartnerStatus = "active"
+	// ExchangePartnerStatusInactive is when the exchange partner is inactive
+	ExchangePartnerStatusInactive ExchangePartnerStatus = "inactive"
+)
+
+const (
+	// ExchangeStatusActive is when the exchange is active
+	ExchangeStatusActive ExchangeStatus = "active"
+	// ExchangeStatusRemoved is when the exchange has been removed
+	ExchangeStatusRemoved ExchangeStatus = "removed"
+)
+
+// ExchangePartnerService is the exchange partner service interface
+//
+// see: https://docsv2.dwolla.com/#exchange-partners
+type ExchangePartnerService interface {
+	List(context.Context) (*ExchangePartners, error)
+	Retrieve(context.Context, string) (*ExchangePartner, error)
+}
+
+// ExchangePartnerServiceOp is an implementation of the exchange partner
+// service interface
+type ExchangePartnerServiceOp struct {
+	client *Client
+}
+
+// ExchangeService is the exchange service interface
+//
+// see: https://docsv2.dwolla.com/#exchanges
+type ExchangeService interface {
+	Retrieve(context.Context, string) (*Exchange, error)
+	RetrieveSession(context.Context, string) (*ExchangeSession, error)
+}
+
+// ExchangeServiceOp is an implementation of the exchange service interface
+type ExchangeServiceOp struct {
+	client *Client
+}
+
+// ExchangePartnerStatus is an exchange partner's status
+type ExchangePartnerStatus string
+
+// ExchangePartner is an open banking partner used to link bank accounts
+type ExchangePartner struct {
+	Resource
+	ID      string                `json:"id"`
+	Name    string                `json:"name"`
+	Status  ExchangePartnerStatus `json:"status"`
+	Created string                `json:"created"`
+}
+
+// ExchangePartners is a collection of exchange partners
+type ExchangePartners struct {
+	Collection
+	Embedded map[string][]ExchangePartner `json:"_embedded"`
+}
+
+// FindByName returns the exchange partner with the name, ignoring case
+func (e *ExchangePartners) FindByName(name string) (*ExchangePartner, bool) {
+	for i, partner := range e.Embedded["exchange-partners"] {
+		if strings.EqualFold(partner.Name, name) {
+			return &e.Embedded["exchange-partners"][i], true
+		}
+	}
+
+	return nil, false
+}
+
+// ExchangeSession is a session used to link a bank account through an
+// exchange partner
+//
+// Plaid sessions return an ExternalProviderSessionToken, other partners
+// return an external-provider-session link to redirect the customer to.
+type ExchangeSession struct {
+	Resource
+	ID                           string `json:"id"`
+	Created                      string `json:"created"`
+	ExternalProviderSessionToken string `json:"externalProviderSessionToken,omitempty"`
+}
+
+// ExchangeSessionRequest is an exchange session create request
+type ExchangeSessionRequest struct {
+	Resource
+}
+
+// NewExchangeSessionRequest initializes an exchange session request for the
+// exchange partner href
+//
+// The redirect url is only required for partners that finish in a mobile
+// app, and is omitted when empty.
+func NewExchangeSessionRequest(partner, redirectURL string) *ExchangeSessionRequest {
+	links := Links{"exchange-partner": Link{Href: partner}}
+
+	if redirectURL != "" {
+		links["redirect-url"] = Link{Href: redirectURL}
+	}
+
+	return &ExchangeSessionRequest{Resource: Resource{Links: links}}
+}
+
+// ExchangeStatus is an exchange's status
+type ExchangeStatus string
+
+// Exchange is a customer's bank account authorization from an exchange
+// partner
+type Exchange struct {
+	Resource
+	ID      string         `json:"id"`
+	Status  ExchangeStatus `json:"status"`
+	Created string         `json:"created"`
+}
+
+// Exchanges is a collection of exchanges
+type Exchanges struct {
+	Collection
+	Embedded map[string][]Exchange `json:"_embedded"`
+}
+
+// FinicityExchange is the Finicity account authorization for an exchange
+type FinicityExchange struct {
+	ProfileID      int    `json:"profile"`
+	ReceiptID      string `json:"receiptId"`
+	ReceiptVersion int    `json:"receiptVersion"`
+	CustomerID     string `json:"customerId"`
+	AccountID      string `json:"accountId"`
+	Timestamp      int64  `json:"timestamp"`
+	TTL            int64  `json:"ttl"`
+}
+
+// ExchangeRequest is an exchange create request
+//
+// Plaid, MX and Flinks exchanges use the Token from the partner; Finicity
+// exchanges use the Finicity authorization.
+type ExchangeRequest struct {
+	Resource
+	Token    string            `json:"token,omitempty"`
+	Finicity *FinicityExchange `json:"finicity,omitempty"`
+}
+
+// NewExchangeRequest initializes an exchange request for the exchange
+// partner href and partner token
+func NewExchangeRequest(partner, token string) *ExchangeRequest {
+	return &ExchangeRequest{
+		Resource: Resource{Links: Links{"exchange-partner": Link{Href: partner}}},
+		Token:    token,
+	}
+}
+
+// NewExchangeFundingSourceRequest initializes a funding source request that
+// links the bank account authorized by the exchange href
+//
+// see: https://docsv2.dwolla.com/#create-a-funding-source-for-a-customer
+func NewExchangeFundingSourceRequest(exchange, name string, accountType FundingSourceBankAccountType) *FundingSourceRequest {
+	return &FundingSourceRequest{
+		Resource:        Resource{Links: Links{"exchange": Link{Href: exchange}}},
+		BankAccountType: accountType,
+		Name:            name,
+	}
+}
+
+// List returns the available exchange partners
+//
+// see: https://docsv2.dwolla.com/#list-exchange-partners
+func (e *ExchangePartnerServiceOp) List(ctx context.Context) (*ExchangePartners, error) {
+	var partners ExchangePartners
+
+	if err := e.client.Get(ctx, "exchange-partners", nil, nil, &partners); err != nil {
+		return nil, err
+	}
+
+	partners.client = e.client
+
+	for i := range partners.Embedded["exchange-partners"] {
+		partners.Embedded["exchange-partners"][i].client = e.client
+	}
+
+	return &partners, nil
+}
+
+// Retrieve retrieves the exchange partner matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-exchange-partner
+func (e *ExchangePartnerServiceOp) Retrieve(ctx context.Context, id string) (*ExchangePartner, error) {
+	var partner ExchangePartner
+
+	if err := e.client.Get(ctx, fmt.Sprintf("exchange-partners/%s", id), nil, nil, &partner); err != nil {
+		return nil, err
+	}
+
+	partner.client = e.client
+
+	return &partner, nil
+}
+
+// Retrieve retrieves the exchange matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-an-exchange
+func (e *ExchangeServiceOp) Retrieve(ctx context.Context, id string) (*Exchange, error) {
+	var exchange Exchange
+
+	if err := e.client.Get(ctx, fmt.Sprintf("exchanges/%s", id), nil, nil, &exchange); err != nil {
+		return nil, err
+	}
+
+	exchange.client = e.client
+
+	return &exchange, nil
+}
+
+// RetrieveSession retrieves the exchange session matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-an-exchange-session
+func (e *ExchangeServiceOp) RetrieveSession(ctx context.Context, id string) (*ExchangeSession, error) {
+	var session ExchangeSession
+
+	if err := e.client.Get(ctx, fmt.Sprintf("exchange-sessions/%s", id), nil, nil, &session); err != nil {
+		return nil, err
+	}
+
+	session.client = e.client
+
+	return &session, nil
+}
+
+// CreateFundingSource creates a funding source for the exchange's bank
+// account
+//
+// An accepted on-demand authorization is attached when one is given, and is
+// required when Client.RequireOnDemandAuthorization is set. The request goes
+// through the same checks as Customer.CreateFundingSource.
+//
+// see: https://docsv2.dwolla.com/#create-a-funding-source-for-a-customer
+func (e *Exchange) CreateFundingSource(ctx context.Context, name string, accountType FundingSourceBankAccountType, acceptance *OnDemandAuthorizationAcceptance) (*FundingSource, error) {
+	var source FundingSource
+
+	if _, ok := e.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	if _, ok := e.Links["customer"]; !ok {
+		return nil, errors.New("No customer resource link")
+	}
+
+	body := NewExchangeFundingSourceRequest(e.Links["self"].Href, name, accountType)
+
+	if acceptance != nil {
+		if err := acceptance.Validate(); err != nil {
+			return nil, err
+		}
+
+		body.SetOnDemandAuthorization(acceptance.Authorization)
+	}
+
+	if err := e.client.checkOnDemandAuthorization(body); err != nil {
+		return nil, err
+	}
+
+	if err := e.client.validateFundingSource(body); err != nil {
+		return nil, err
+	}
+
+	if err := e.client.Post(ctx, fmt.Sprintf("%s/funding-sources", e.Links["customer"].Href), body, nil, &source); err != nil {
+		return nil, err
+	}
+
+	source.client = e.client
+
+	return &source, nil
+}
+
+// RetrieveExchangePartner retrieves the exchange's partner
+func (e *Exchange) RetrieveExchangePartner(ctx context.Context) (*ExchangePartner, error) {
+	var partner ExchangePartner
+
+	if _, ok := e.Links["exchange-partner"]; !ok {
+		return nil, errors.New("No exchange partner resource link")
+	}
+
+	if err := e.client.Get(ctx, e.Links["exchange-partner"].Href, nil, nil, &partner); err != nil {
+		return nil, err
+	}
+
+	partner.client = e.client
+
+	return &partner, nil
+}
@@ -0,0 +1,238 @@
+package dwolla
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testExchangePartner = "https://api-sandbox.dwolla.com/exchange-partners/bca8d065-49a5-475b-a6b4-509bc8504d22"
+
+func TestExchangePartnerServiceList(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "exchange-partners.json"))
+	res, err := c.ExchangePartner.List(ctx)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.Total, 3)
+
+	partner, ok := res.FindByName("finicity")
+
+	assert.True(t, ok)
+	assert.Equal(t, partner.ID, "bca8d065-49a5-475b-a6b4-509bc8504d22")
+	assert.Equal(t, partner.Status, ExchangePartnerStatusActive)
+
+	_, ok = res.FindByName(ExchangePartnerFlinks)
+
+	assert.False(t, ok)
+}
+
+func TestExchangePartnerServiceListError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+	res, err := c.ExchangePartner.List(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestExchangePartnerServiceRetrieve(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "exchange-partner.json"))
+	res, err := c.ExchangePartner.Retrieve(ctx, "bca8d065-49a5-475b-a6b4-509bc8504d22")
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Name, ExchangePartnerFinicity)
+}
+
+func TestExchangeServiceRetrieve(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "exchange.json"))
+	res, err := c.Exchange.Retrieve(ctx, "6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a")
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Status, ExchangeStatusActive)
+
+	c = newMockClient(200, filepath.Join("testdata", "exchange-session.json"))
+	session, err := c.Exchange.RetrieveSession(ctx, "fcd15e5f-8d13-4570-a9b7-7fb49e55941d")
+
+	assert.Nil(t, err)
+	assert.Contains(t, session.Links, "external-provider-session")
+}
+
+func TestExchangeServiceRetrieveError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+	res, err := c.Exchange.Retrieve(ctx, "6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a")
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	session, err := c.Exchange.RetrieveSession(ctx, "fcd15e5f-8d13-4570-a9b7-7fb49e55941d")
+
+	assert.Error(t, err)
+	assert.Nil(t, session)
+}
+
+func TestExchangeRequestMarshal(t *testing.T) {
+	data, err := json.Marshal(NewExchangeRequest(testExchangePartner, "processor-sandbox-123"))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(data), `{"_links":{"exchange-partner":{"href":"`+testExchangePartner+`"}},"token":"processor-sandbox-123"}`)
+
+	data, err = json.Marshal(NewExchangeSessionRequest(testExchangePartner, ""))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(data), `{"_links":{"exchange-partner":{"href":"`+testExchangePartner+`"}}}`)
+
+	data, err = json.Marshal(NewExchangeSessionRequest(testExchangePartner, "https://example.com/return"))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(data), `{"_links":{"exchange-partner":{"href":"`+testExchangePartner+`"},"redirect-url":{"href":"https://example.com/return"}}}`)
+
+	data, err = json.Marshal(NewExchangeFundingSourceRequest("https://api-sandbox.dwolla.com/exchanges/6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a", "Checking", FundingSourceBankAccountTypeChecking))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(data), `{"_links":{"exchange":{"href":"https://api-sandbox.dwolla.com/exchanges/6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a"}},"bankAccountType":"checking","name":"Checking"}`)
+}
+
+func TestCustomerCreateExchangeSession(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "exchange-session.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.CreateExchangeSession(ctx, NewExchangeSessionRequest(testExchangePartner, ""))
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	res, err = customer.CreateExchangeSession(ctx, NewExchangeSessionRequest(testExchangePartner, ""))
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "fcd15e5f-8d13-4570-a9b7-7fb49e55941d")
+}
+
+func TestCustomerCreateExchange(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "exchange.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.CreateExchange(ctx, NewExchangeRequest(testExchangePartner, "token"))
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	res, err = customer.CreateExchange(ctx, &ExchangeRequest{
+		Resource: Resource{Links: Links{"exchange-partner": Link{Href: testExchangePartner}}},
+		Finicity: &FinicityExchange{ProfileID: 3, ReceiptID: "crcpt_6e3dd5e0", ReceiptVersion: 1, CustomerID: "7017837424", AccountID: "7031964441"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a")
+}
+
+func TestCustomerListExchanges(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "exchanges.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.ListExchanges(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"}}
+	res, err = customer.ListExchanges(ctx)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Embedded["exchanges"], 1)
+	assert.Equal(t, res.Embedded["exchanges"][0].Status, ExchangeStatusActive)
+}
+
+func TestExchangeCreateFundingSource(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+		"GET /exchange-partners/bca8d065-49a5-475b-a6b4-509bc8504d22":          {200, filepath.Join("testdata", "exchange-partner.json")},
+	})
+
+	exchange, _ := newMockClient(200, filepath.Join("testdata", "exchange.json")).Exchange.Retrieve(ctx, "6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a")
+	exchange.client = c
+
+	source, err := exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, source)
+
+	body, _ := ioutil.ReadAll(mc.requestLog()[0].Body)
+
+	var req FundingSourceRequest
+
+	assert.Nil(t, json.Unmarshal(body, &req))
+	assert.Equal(t, req.Links["exchange"].Href, exchange.Links["self"].Href)
+
+	partner, err := exchange.RetrieveExchangePartner(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, partner.Name, ExchangePartnerFinicity)
+}
+
+func TestExchangeCreateFundingSourceError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	exchange := &Exchange{Resource: Resource{client: c}}
+	res, err := exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	exchange.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/exchanges/6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a"}}
+	res, err = exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	partner, err := exchange.RetrieveExchangePartner(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, partner)
+}
+
+func TestExchangeCreateFundingSourceOnDemandAuthorization(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	exchange, _ := newMockClient(200, filepath.Join("testdata", "exchange.json")).Exchange.Retrieve(ctx, "6bc9109a-6a7e-4d29-8b1a-c5f1ee4e4e0a")
+	exchange.client = c
+	c.RequireOnDemandAuthorization = true
+
+	res, err := exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, nil)
+
+	assert.Equal(t, ErrOnDemandAuthorizationRequired, err)
+	assert.Nil(t, res)
+
+	res, err = exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, &OnDemandAuthorizationAcceptance{Authorization: testOnDemandAuthorization})
+
+	assert.Equal(t, []string{"/bodyText", "/acceptedAt", "/ipAddress"}, validationErrorPaths(err))
+	assert.Nil(t, res)
+	assert.Len(t, mc.requestLog(), 0)
+
+	acceptance := &OnDemandAuthorizationAcceptance{
+		Authorization: testOnDemandAuthorization,
+		BodyText:      "I agree",
+		AcceptedAt:    time.Now(),
+		IPAddress:     "203.0.113.7",
+	}
+
+	res, err = exchange.CreateFundingSource(ctx, "Checking", FundingSourceBankAccountTypeChecking, acceptance)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+
+	body, _ := ioutil.ReadAll(mc.requestLog()[0].Body)
+
+	var req FundingSourceRequest
+
+	assert.Nil(t, json.Unmarshal(body, &req))
+	assert.Equal(t, testOnDemandAuthorization, req.Links["on-demand-authorization"].Href)
+	assert.Equal(t, exchange.Links["self"].Href, req.Links["exchange"].Href)
+}
@@ -0,0 +1,315 @@
+package dwolla
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultFingerprintScanPageSize is the number of customers requested per
+// page when a scanner has no page size set
+const DefaultFingerprintScanPageSize = 200
+
+// FingerprintMatch is a customer's funding source found in a fingerprint
+// index
+type FingerprintMatch struct {
+	Customer      *Customer
+	FundingSource *FundingSource
+}
+
+// FingerprintCluster is a bank account, identified by its fingerprint, that
+// is attached to more than one customer
+type FingerprintCluster struct {
+	Fingerprint string
+	CustomerIDs []string
+	Matches     []FingerprintMatch
+}
+
+// DuplicateFundingSourceWarning reports that a bank account already belongs
+// to other customers
+//
+// It implements error so callers can choose to block the funding source.
+type DuplicateFundingSourceWarning struct {
+	Fingerprint string
+	Matches     []FingerprintMatch
+}
+
+// Error implements the error interface
+func (w *DuplicateFundingSourceWarning) Error() string {
+	ids := make([]string, 0, len(w.Matches))
+
+	for _, match := range w.Matches {
+		ids = append(ids, match.Customer.ID)
+	}
+
+	return fmt.Sprintf("Bank account is already attached to customers: %s", strings.Join(ids, ", "))
+}
+
+// FingerprintIndex indexes customers' funding sources by fingerprint
+//
+// Dwolla computes fingerprints itself, so a new funding source's
+// fingerprint is only known after it is created. To pre-check requests,
+// the index also remembers which fingerprint each bank account created
+// through Record received, keyed by an HMAC of its routing and account
+// numbers with a random key generated for the index. The numbers themselves
+// are never stored. Bank accounts the index has not recorded can be checked
+// after they are created with Customer.CreateCheckedFundingSource.
+//
+// An index is not safe for concurrent use.
+type FingerprintIndex struct {
+	fingerprints map[string][]FingerprintMatch
+	accounts     map[string]string
+	key          []byte
+}
+
+// NewFingerprintIndex initializes an empty fingerprint index
+//
+// An error is returned if the index's random key can not be generated.
+func NewFingerprintIndex() (*FingerprintIndex, error) {
+	key := make([]byte, sha256.Size)
+
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return &FingerprintIndex{
+		fingerprints: map[string][]FingerprintMatch{},
+		accounts:     map[string]string{},
+		key:          key,
+	}, nil
+}
+
+// Add indexes the customer's funding sources
+//
+// Funding sources without a fingerprint, such as balances, are skipped, as
+// are funding sources already in the index.
+func (i *FingerprintIndex) Add(customer *Customer, sources ...FundingSource) {
+	for j := range sources {
+		source := &sources[j]
+
+		if source.Fingerprint == "" || i.contains(source) {
+			continue
+		}
+
+		i.fingerprints[source.Fingerprint] = append(i.fingerprints[source.Fingerprint], FingerprintMatch{Customer: customer, FundingSource: source})
+	}
+}
+
+// Record indexes a funding source created from the request, so later
+// requests for the same bank account can be pre-checked
+func (i *FingerprintIndex) Record(customer *Customer, body *FundingSourceRequest, source *FundingSource) {
+	i.Add(customer, *source)
+
+	if source.Fingerprint != "" && body.RoutingNumber != "" && body.AccountNumber != "" {
+		i.accounts[i.bankAccountKey(body.RoutingNumber, body.AccountNumber)] = source.Fingerprint
+	}
+}
+
+// Len returns the number of fingerprints in the index
+func (i *FingerprintIndex) Len() int {
+	return len(i.fingerprints)
+}
+
+// Lookup returns the funding sources with the fingerprint
+func (i *FingerprintIndex) Lookup(fingerprint string) []FingerprintMatch {
+	return i.fingerprints[fingerprint]
+}
+
+// Shared returns the funding sources with the fingerprint that belong to
+// customers other than the customer id
+func (i *FingerprintIndex) Shared(customerID, fingerprint string) []FingerprintMatch {
+	var shared []FingerprintMatch
+
+	for _, match := range i.fingerprints[fingerprint] {
+		if !strings.EqualFold(match.Customer.ID, customerID) {
+			shared = append(shared, match)
+		}
+	}
+
+	return shared
+}
+
+// Clusters returns the fingerprints attached to more than one customer,
+// ordered by fingerprint
+func (i *FingerprintIndex) Clusters() []FingerprintCluster {
+	var clusters []FingerprintCluster
+
+	for fingerprint, matches := range i.fingerprints {
+		seen := map[string]bool{}
+		cluster := FingerprintCluster{Fingerprint: fingerprint, Matches: matches}
+
+		for _, match := range matches {
+			id := strings.ToLower(match.Customer.ID)
+
+			if !seen[id] {
+				seen[id] = true
+				cluster.CustomerIDs = append(cluster.CustomerIDs, match.Customer.ID)
+			}
+		}
+
+		if len(cluster.CustomerIDs) > 1 {
+			sort.Strings(cluster.CustomerIDs)
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	sort.Slice(clusters, func(a, b int) bool {
+		return clusters[a].Fingerprint < clusters[b].Fingerprint
+	})
+
+	return clusters
+}
+
+// contains returns true if the funding source is already indexed
+func (i *FingerprintIndex) contains(source *FundingSource) bool {
+	for _, match := range i.fingerprints[source.Fingerprint] {
+		if match.FundingSource.ID == source.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FingerprintScanner builds a fingerprint index from every customer's
+// funding sources, including removed ones
+//
+// Options filter the customers scanned. A nil Options scans all customers.
+type FingerprintScanner struct {
+	Client   *Client
+	Options  *CustomerListOptions
+	PageSize int
+}
+
+// Scan walks every page of customers and indexes their funding sources
+func (s *FingerprintScanner) Scan(ctx context.Context) (*FingerprintIndex, error) {
+	index, err := NewFingerprintIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	options := CustomerListOptions{}
+	if s.Options != nil {
+		options = *s.Options
+	}
+
+	if options.Limit <= 0 {
+		options.Limit = s.PageSize
+	}
+
+	if options.Limit <= 0 {
+		options.Limit = DefaultFingerprintScanPageSize
+	}
+
+	customers, err := s.Client.Customer.List(ctx, options.Values())
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for j := range customers.Embedded["customers"] {
+			customer := &customers.Embedded["customers"][j]
+
+			sources, err := customer.ListFundingSources(ctx, true)
+			if err != nil {
+				return nil, err
+			}
+
+			index.Add(customer, sources.Embedded["funding-sources"]...)
+		}
+
+		next, ok := customers.Links["next"]
+		if !ok || len(customers.Embedded["customers"]) == 0 {
+			return index, nil
+		}
+
+		customers = &Customers{}
+
+		if err := s.Client.Get(ctx, next.Href, nil, nil, customers); err != nil {
+			return nil, err
+		}
+
+		for j := range customers.Embedded["customers"] {
+			customers.Embedded["customers"][j].client = s.Client
+		}
+	}
+}
+
+// PrecheckFundingSource returns a warning if the request's bank account is
+// already attached to other customers in the index
+//
+// Only bank accounts recorded in the index with FingerprintIndex.Record can
+// be recognized before they are created; use CreateCheckedFundingSource to
+// check the others. Nil is returned when the account is unknown or belongs
+// only to this customer.
+func (c *Customer) PrecheckFundingSource(index *FingerprintIndex, body *FundingSourceRequest) *DuplicateFundingSourceWarning {
+	if body.RoutingNumber == "" || body.AccountNumber == "" {
+		return nil
+	}
+
+	fingerprint, ok := index.accounts[index.bankAccountKey(body.RoutingNumber, body.AccountNumber)]
+	if !ok {
+		return nil
+	}
+
+	shared := index.Shared(c.ID, fingerprint)
+	if len(shared) == 0 {
+		return nil
+	}
+
+	return &DuplicateFundingSourceWarning{Fingerprint: fingerprint, Matches: shared}
+}
+
+// CreateCheckedFundingSource creates a funding source for the customer and
+// returns a warning if its fingerprint is already attached to other
+// customers in the index
+//
+// The request is pre-checked first, so bank accounts recorded in the index
+// are warned about without being created. Otherwise the funding source is
+// created and its fingerprint compared with the index. If removeDuplicate
+// is true a duplicate funding source is removed again, otherwise it is kept
+// and recorded in the index.
+//
+// see: https://docsv2.dwolla.com/#create-a-funding-source-for-a-customer
+func (c *Customer) CreateCheckedFundingSource(ctx context.Context, index *FingerprintIndex, body *FundingSourceRequest, removeDuplicate bool) (*FundingSource, *DuplicateFundingSourceWarning, error) {
+	if warning := c.PrecheckFundingSource(index, body); warning != nil && removeDuplicate {
+		return nil, warning, nil
+	}
+
+	source, err := c.CreateFundingSource(ctx, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warning *DuplicateFundingSourceWarning
+
+	if shared := index.Shared(c.ID, source.Fingerprint); source.Fingerprint != "" && len(shared) > 0 {
+		warning = &DuplicateFundingSourceWarning{Fingerprint: source.Fingerprint, Matches: shared}
+	}
+
+	if warning != nil && removeDuplicate {
+		if err := source.Remove(ctx); err != nil {
+			return source, warning, err
+		}
+
+		return source, warning, nil
+	}
+
+	index.Record(c, body, source)
+
+	return source, warning, nil
+}
+
+// bankAccountKey returns an HMAC identifying the bank account within the
+// index
+func (i *FingerprintIndex) bankAccountKey(routingNumber, accountNumber string) string {
+	mac := hmac.New(sha256.New, i.key)
+	mac.Write([]byte(routingNumber + ":" + accountNumber))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,198 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFingerprintShared = "5012989b55af15400e8102f95d2ec5e7ce3aef45c01613280d80a236dd8d6c3a"
+
+func TestFingerprintScannerScan(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers?limit=2":                               {200, filepath.Join("testdata", "customers-page-1.json")},
+		"GET /customers?limit=2&offset=2":                      {200, filepath.Join("testdata", "customers-page-2.json")},
+		"GET /customers/" + testCustomerA + "/funding-sources": {200, filepath.Join("testdata", "funding-sources-customer-a.json")},
+		"GET /customers/" + testCustomerB + "/funding-sources": {200, filepath.Join("testdata", "funding-sources-customer-b.json")},
+		"GET /customers/" + testCustomerC + "/funding-sources": {200, filepath.Join("testdata", "funding-sources-customer-c.json")},
+	})
+
+	scanner := &FingerprintScanner{Client: c, PageSize: 2}
+	index, err := scanner.Scan(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, index.Len())
+	assert.Equal(t, 2, countMockRequests(mc, "GET", "/customers"))
+
+	for _, req := range mc.requestLog() {
+		if req.URL.Path != "/customers" {
+			assert.Equal(t, "true", req.URL.Query().Get("removed"))
+		}
+	}
+
+	matches := index.Lookup(testFingerprintShared)
+
+	assert.Len(t, matches, 2)
+	assert.Equal(t, testCustomerA, matches[0].Customer.ID)
+	assert.Equal(t, testCustomerB, matches[1].Customer.ID)
+	assert.True(t, matches[1].FundingSource.Removed)
+
+	clusters := index.Clusters()
+
+	assert.Len(t, clusters, 1)
+	assert.Equal(t, testFingerprintShared, clusters[0].Fingerprint)
+	assert.Equal(t, []string{testCustomerA, testCustomerB}, clusters[0].CustomerIDs)
+	assert.Len(t, clusters[0].Matches, 2)
+
+	shared := index.Shared(testCustomerA, testFingerprintShared)
+
+	assert.Len(t, shared, 1)
+	assert.Equal(t, "b2000000-0000-4000-8000-000000000001", shared[0].FundingSource.ID)
+}
+
+func TestFingerprintScannerScanError(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers?limit=2":                               {200, filepath.Join("testdata", "customers-page-1.json")},
+		"GET /customers?limit=2&offset=2":                      {200, filepath.Join("testdata", "customers-page-2.json")},
+		"GET /customers/" + testCustomerA + "/funding-sources": {200, filepath.Join("testdata", "funding-sources-customer-a.json")},
+		"GET /customers/" + testCustomerB + "/funding-sources": {200, filepath.Join("testdata", "funding-sources-customer-b.json")},
+	})
+
+	scanner := &FingerprintScanner{Client: c, PageSize: 2}
+
+	index, err := scanner.Scan(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, index)
+}
+
+func TestFingerprintIndexAdd(t *testing.T) {
+	index, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	customer := &Customer{ID: testCustomerA}
+	source := FundingSource{ID: "a1", Fingerprint: "fp"}
+
+	index.Add(customer, source, source, FundingSource{ID: "balance", Type: FundingSourceTypeBalance})
+
+	assert.Equal(t, 1, index.Len())
+	assert.Len(t, index.Lookup("fp"), 1)
+
+	index.Add(customer, FundingSource{ID: "a2", Fingerprint: "fp"})
+
+	assert.Len(t, index.Lookup("fp"), 2)
+	assert.Len(t, index.Clusters(), 0)
+}
+
+func TestCustomerPrecheckFundingSource(t *testing.T) {
+	index, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	index.Add(&Customer{ID: testCustomerA}, FundingSource{ID: "a1000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared})
+	index.Add(&Customer{ID: testCustomerB}, FundingSource{ID: "b2000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared, Removed: true})
+
+	customerA := &Customer{ID: testCustomerA}
+	customerC := &Customer{ID: testCustomerC}
+
+	body := newTestFundingSourceRequest()
+
+	assert.Nil(t, customerC.PrecheckFundingSource(index, body))
+
+	index.Record(customerA, body, &FundingSource{ID: "a1000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared})
+
+	assert.Len(t, index.Lookup(testFingerprintShared), 2)
+
+	warning := customerC.PrecheckFundingSource(index, body)
+
+	assert.NotNil(t, warning)
+	assert.Equal(t, testFingerprintShared, warning.Fingerprint)
+	assert.Len(t, warning.Matches, 2)
+	assert.Contains(t, warning.Error(), testCustomerA)
+	assert.Contains(t, warning.Error(), testCustomerB)
+	assert.NotContains(t, warning.Error(), body.AccountNumber)
+
+	warning = customerA.PrecheckFundingSource(index, body)
+
+	assert.NotNil(t, warning)
+	assert.Len(t, warning.Matches, 1)
+
+	other := newTestFundingSourceRequest()
+	other.AccountNumber = "9876543210"
+
+	assert.Nil(t, customerC.PrecheckFundingSource(index, other))
+	assert.Nil(t, customerC.PrecheckFundingSource(index, &FundingSourceRequest{Name: "Renamed"}))
+}
+
+func TestCustomerCreateCheckedFundingSource(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/" + testCustomerC + "/funding-sources": {201, filepath.Join("testdata", "funding-source-created.json")},
+	})
+
+	index, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	index.Add(&Customer{ID: testCustomerA}, FundingSource{ID: "a1000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared})
+	index.Add(&Customer{ID: testCustomerB}, FundingSource{ID: "b2000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared, Removed: true})
+
+	customerC := &Customer{ID: testCustomerC, Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL("customers/" + testCustomerC + "/funding-sources")}}}}
+
+	source, warning, err := customerC.CreateCheckedFundingSource(ctx, index, newTestFundingSourceRequest(), false)
+
+	assert.Nil(t, err)
+	assert.False(t, source.Removed)
+	assert.NotNil(t, warning)
+	assert.Equal(t, testFingerprintShared, warning.Fingerprint)
+	assert.Len(t, warning.Matches, 2)
+	assert.Len(t, index.Lookup(testFingerprintShared), 3)
+	assert.Equal(t, 0, countMockRequests(mc, "POST", "/funding-sources/49dbaa24-1580-4b1c-8b58-24e26656fa31"))
+
+	warning = customerC.PrecheckFundingSource(index, newTestFundingSourceRequest())
+
+	assert.NotNil(t, warning)
+}
+
+func TestCustomerCreateCheckedFundingSourceRemove(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /customers/" + testCustomerC + "/funding-sources":      {201, filepath.Join("testdata", "funding-source-created.json")},
+		"POST /funding-sources/49dbaa24-1580-4b1c-8b58-24e26656fa31": {200, filepath.Join("testdata", "funding-source-removed.json")},
+	})
+
+	index, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	index.Add(&Customer{ID: testCustomerA}, FundingSource{ID: "a1000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared})
+	index.Add(&Customer{ID: testCustomerB}, FundingSource{ID: "b2000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared, Removed: true})
+
+	customerC := &Customer{ID: testCustomerC, Resource: Resource{client: c, Links: Links{"funding-sources": Link{Href: c.BuildAPIURL("customers/" + testCustomerC + "/funding-sources")}}}}
+
+	source, warning, err := customerC.CreateCheckedFundingSource(ctx, index, newTestFundingSourceRequest(), true)
+
+	assert.Nil(t, err)
+	assert.True(t, source.Removed)
+	assert.NotNil(t, warning)
+	assert.Len(t, index.Lookup(testFingerprintShared), 2)
+	assert.Equal(t, 1, countMockRequests(mc, "POST", "/funding-sources/49dbaa24-1580-4b1c-8b58-24e26656fa31"))
+	assert.Nil(t, customerC.PrecheckFundingSource(index, newTestFundingSourceRequest()))
+
+	index.Record(&Customer{ID: testCustomerA}, newTestFundingSourceRequest(), &FundingSource{ID: "a1000000-0000-4000-8000-000000000001", Fingerprint: testFingerprintShared})
+
+	source, warning, err = customerC.CreateCheckedFundingSource(ctx, index, newTestFundingSourceRequest(), true)
+
+	assert.Nil(t, err)
+	assert.Nil(t, source)
+	assert.NotNil(t, warning)
+	assert.Equal(t, 1, countMockRequests(mc, "POST", "/customers/"+testCustomerC+"/funding-sources"))
+}
+
+func TestFingerprintIndexBankAccountKey(t *testing.T) {
+	a, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	b, err := NewFingerprintIndex()
+	assert.Nil(t, err)
+
+	assert.Equal(t, a.bankAccountKey("222222226", "0123456789"), a.bankAccountKey("222222226", "0123456789"))
+	assert.NotEqual(t, a.bankAccountKey("222222226", "0123456789"), b.bankAccountKey("222222226", "0123456789"))
+	assert.NotEqual(t, a.bankAccountKey("222222226", "0123456789"), a.bankAccountKey("222222226", "9876543210"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 )
 
 const (
@@ -20,6 +21,10 @@ const (
 	FundingSourceStatusVerified FundingSourceStatus = "verified"
 )
 
+// ErrNoBalanceFundingSource is returned when a customer or account has no
+// balance funding source
+var ErrNoBalanceFundingSource = errors.New("no balance funding source")
+
 const (
 	// FundingSourceTypeBank is when the funding source is a bank account
 	FundingSourceTypeBank FundingSourceType = "bank"
@@ -69,6 +74,20 @@ type FundingSources struct {
 	Embedded map[string][]FundingSource `json:"_embedded"`
 }
 
+// Balance returns the collection's balance funding source, skipping removed
+// funding sources
+func (f *FundingSources) Balance() (*FundingSource, error) {
+	for i := range f.Embedded["funding-sources"] {
+		source := &f.Embedded["funding-sources"][i]
+
+		if source.Type == FundingSourceTypeBalance && !source.Removed {
+			return source, nil
+		}
+	}
+
+	return nil, ErrNoBalanceFundingSource
+}
+
 // FundingSourceBankAccountType is a dwolla bank account type enum
 type FundingSourceBankAccountType string
 
@@ -81,6 +100,9 @@ type FundingSourceBalance struct {
 }
 
 // FundingSourceRequest is a funding source request
+//
+// Bank accounts linked through an exchange partner are created with
+// NewExchangeFundingSourceRequest.
 type FundingSourceRequest struct {
 	Resource
 	RoutingNumber   string                       `json:"routingNumber,omitempty"`
@@ -95,7 +117,8 @@ type FundingSourceRequest struct {
 // FundingSourceToken is a funding source dwolla.js token
 type FundingSourceToken struct {
 	Resource
-	Token string `json:"token"`
+	Token  string `json:"token"`
+	issued time.Time
 }
 
 // Retrieve retrieves a funding source with the matching id
@@ -268,11 +291,17 @@ func (f *FundingSource) Update(ctx context.Context, body *FundingSourceRequest)
 
 // VerifyMicroDeposits verifies micro deposit amounts
 //
+// The amounts are validated before they are sent.
+//
 // see: https://docsv2.dwolla.com/#verify-micro-deposits
 func (f *FundingSource) VerifyMicroDeposits(ctx context.Context, body *MicroDepositRequest) error {
 	if _, ok := f.Links["verify-micro-deposits"]; !ok {
 		return errors.New("No verify micro deposits resource link")
 	}
 
+	if err := f.client.validate(body); err != nil {
+		return err
+	}
+
 	return f.client.Post(ctx, f.Links["verify-micro-deposits"].Href, body, nil, nil)
 }
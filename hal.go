@@ -76,3 +76,25 @@ type Collection struct {
 func Unmarshal(data []byte, container interface{}) error {
 	return json.Unmarshal(data, container)
 }
+
+// validationErrors collects client side validation errors
+type validationErrors []HALError
+
+// add records a validation error for the path
+func (v *validationErrors) add(code, path, message string) {
+	*v = append(*v, HALError{Code: code, Message: message, Path: path})
+}
+
+// err returns the collected errors as a ValidationError, or nil if there
+// are none
+func (v validationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return ValidationError{
+		Code:     "ValidationError",
+		Message:  "Validation error(s) present. See embedded errors list for more details.",
+		Embedded: HALErrors{"errors": v},
+	}
+}
@@ -4,8 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 )
 
+const (
+	// KBAStatusFailed is when the customer failed knowledge based
+	// authentication
+	KBAStatusFailed KBAStatus = "failed"
+	// KBAStatusVerified is when the customer passed knowledge based
+	// authentication
+	KBAStatusVerified KBAStatus = "verified"
+)
+
+// ErrKBAExpired is returned when a knowledge based authentication session
+// has expired and a new one must be initiated
+var ErrKBAExpired = errors.New("kba session has expired")
+
 // KBAService is the kba service interface
 //
 // see: https://docs.dwolla.com/#knowledge-based-authentication-kba
@@ -49,6 +63,68 @@ type KBAQuestionAnswer struct {
 	AnswerID   string `json:"answerId"`
 }
 
+// KBAStatus is the outcome of a knowledge based authentication attempt
+type KBAStatus string
+
+// KBAResult is the result of a knowledge based authentication attempt
+//
+// CustomerStatus and Customer are set from the customer after the attempt.
+type KBAResult struct {
+	Resource
+	VerificationStatus KBAStatus      `json:"verificationStatus"`
+	CustomerStatus     CustomerStatus `json:"-"`
+	Customer           *Customer      `json:"-"`
+}
+
+// Verified returns true if the customer passed knowledge based
+// authentication
+func (r *KBAResult) Verified() bool {
+	return r.VerificationStatus == KBAStatusVerified
+}
+
+// KBAPrompter shows knowledge based authentication questions and returns
+// the chosen answer ids keyed by question id
+//
+// A web form can render every question at once and return its submitted
+// values, while KBAQuestionPrompterFunc asks the questions one at a time.
+type KBAPrompter interface {
+	Prompt(context.Context, []KBAQuestion) (map[string]string, error)
+}
+
+// KBAPrompterFunc is a function that implements KBAPrompter
+type KBAPrompterFunc func(context.Context, []KBAQuestion) (map[string]string, error)
+
+// Prompt calls the function
+func (f KBAPrompterFunc) Prompt(ctx context.Context, questions []KBAQuestion) (map[string]string, error) {
+	return f(ctx, questions)
+}
+
+// KBAQuestionPrompterFunc is a function that asks a single question and
+// returns the chosen answer id, such as a command line prompt
+//
+// index is the question's zero based position among the questions.
+type KBAQuestionPrompterFunc func(ctx context.Context, index int, question KBAQuestion) (string, error)
+
+// Prompt asks each question in order
+func (f KBAQuestionPrompterFunc) Prompt(ctx context.Context, questions []KBAQuestion) (map[string]string, error) {
+	answers := make(map[string]string, len(questions))
+
+	for i, question := range questions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		answer, err := f(ctx, i, question)
+		if err != nil {
+			return nil, err
+		}
+
+		answers[question.ID] = answer
+	}
+
+	return answers, nil
+}
+
 // Retrieve retrieves a knowledge based authentication session
 //
 // see: https://docs.dwolla.com/#retrieve-kba-questions
@@ -56,6 +132,10 @@ func (k *KBAServiceOp) Retrieve(ctx context.Context, id string) (*KBA, error) {
 	var kba KBA
 
 	if err := k.client.Get(ctx, fmt.Sprintf("kba/%s", id), nil, nil, &kba); err != nil {
+		if kbaExpired(err) {
+			return nil, ErrKBAExpired
+		}
+
 		return nil, err
 	}
 
@@ -64,13 +144,144 @@ func (k *KBAServiceOp) Retrieve(ctx context.Context, id string) (*KBA, error) {
 	return &kba, nil
 }
 
+// NewRequest builds a verification request from answer ids keyed by
+// question id, in the order the questions were asked
+//
+// Answers to unknown questions are dropped and missing answers are left
+// empty, so that Verify reports them.
+func (k *KBA) NewRequest(answers map[string]string) *KBARequest {
+	body := &KBARequest{}
+
+	for _, question := range k.Questions {
+		body.Answers = append(body.Answers, KBAQuestionAnswer{QuestionID: question.ID, AnswerID: answers[question.ID]})
+	}
+
+	return body
+}
+
+// ValidateRequest checks that every question has exactly one answer and
+// that each answer belongs to its question
+func (k *KBA) ValidateRequest(body *KBARequest) error {
+	var errs validationErrors
+
+	questions := make(map[string]KBAQuestion, len(k.Questions))
+
+	for _, question := range k.Questions {
+		questions[question.ID] = question
+	}
+
+	answered := map[string]bool{}
+
+	for i, answer := range body.Answers {
+		path := fmt.Sprintf("/answers/%d", i)
+
+		question, ok := questions[answer.QuestionID]
+		if !ok {
+			errs.add("Invalid", path+"/questionId", "Question is not part of this KBA session.")
+			continue
+		}
+
+		if answered[answer.QuestionID] {
+			errs.add("Invalid", path+"/questionId", "Question has already been answered.")
+			continue
+		}
+
+		answered[answer.QuestionID] = true
+
+		if answer.AnswerID == "" {
+			errs.add("Required", path+"/answerId", "AnswerId required.")
+		} else if !question.hasAnswer(answer.AnswerID) {
+			errs.add("Invalid", path+"/answerId", "Answer does not belong to the question.")
+		}
+	}
+
+	for _, question := range k.Questions {
+		if !answered[question.ID] {
+			errs.add("Required", "/answers", fmt.Sprintf("Question %s has not been answered.", question.ID))
+		}
+	}
+
+	return errs.err()
+}
+
+// hasAnswer returns true if the answer id is one of the question's answers
+func (q KBAQuestion) hasAnswer(id string) bool {
+	for _, answer := range q.Answers {
+		if answer.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Verify attempts a knowledge based authentication verification
 //
+// The answers are validated against the session's questions before they
+// are sent. ErrKBAExpired is returned if the session has expired. The
+// customer is retrieved after the attempt to report its status; if that
+// fails the result is returned along with the error.
+//
 // see: https://docs.dwolla.com/#verify-kba-questions
-func (k *KBA) Verify(ctx context.Context, body *KBARequest) error {
-	if _, ok := k.Links["self"]; !ok {
-		return errors.New("No self resource link")
+func (k *KBA) Verify(ctx context.Context, body *KBARequest) (*KBAResult, error) {
+	link, ok := k.Links["answer"]
+	if !ok {
+		if link, ok = k.Links["self"]; !ok {
+			return nil, errors.New("No self resource link")
+		}
+	}
+
+	if err := k.client.validate(validatorFunc(func() error { return k.ValidateRequest(body) })); err != nil {
+		return nil, err
+	}
+
+	var result KBAResult
+
+	if err := k.client.Post(ctx, link.Href, body, nil, &result); err != nil {
+		if kbaExpired(err) {
+			return nil, ErrKBAExpired
+		}
+
+		return nil, err
+	}
+
+	result.client = k.client
+
+	if _, ok := result.Links["customer"]; !ok {
+		return &result, nil
 	}
 
-	return k.client.Post(ctx, k.Links["self"].Href, body, nil, nil)
+	var customer Customer
+
+	if err := k.client.Get(ctx, result.Links["customer"].Href, nil, nil, &customer); err != nil {
+		return &result, err
+	}
+
+	customer.client = k.client
+	result.Customer = &customer
+	result.CustomerStatus = customer.Status
+
+	return &result, nil
+}
+
+// Answer shows the questions through the prompter and verifies the
+// answers it returns
+func (k *KBA) Answer(ctx context.Context, prompter KBAPrompter) (*KBAResult, error) {
+	answers, err := prompter.Prompt(ctx, k.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.Verify(ctx, k.NewRequest(answers))
+}
+
+// kbaExpired returns true if the error reports an expired kba session
+//
+// Dwolla reports expired sessions as an InvalidResourceState error whose
+// message says the session has expired. Other invalid states, such as an
+// already answered session, are not expiries.
+func kbaExpired(err error) bool {
+	halError, ok := err.(HALError)
+
+	return ok && halError.Code == "InvalidResourceState" && strings.Contains(strings.ToLower(halError.Message), "expired")
 }
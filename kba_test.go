@@ -0,0 +1,152 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKBA = "/kba/33aa88b1-97df-424d-9043-d5f85809858b"
+
+var testKBAAnswers = map[string]string{"2355953375": "2687969315", "2355953385": "2687969385"}
+
+func TestKBAServiceRetrieve(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "kba.json"))
+	res, err := c.KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Questions, 2)
+	assert.Len(t, res.Questions[0].Answers, 5)
+}
+
+func TestKBAServiceRetrieveError(t *testing.T) {
+	c := newMockClient(403, filepath.Join("testdata", "kba-expired.json"))
+	res, err := c.KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+
+	assert.Equal(t, err, ErrKBAExpired)
+	assert.Nil(t, res)
+
+	c = newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+	_, err = c.KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+
+	assert.IsType(t, HALError{}, err)
+}
+
+func TestKBAValidateRequest(t *testing.T) {
+	kba, err := newMockClient(200, filepath.Join("testdata", "kba.json")).KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+	assert.Nil(t, err)
+
+	assert.Nil(t, kba.ValidateRequest(kba.NewRequest(testKBAAnswers)))
+
+	err = kba.ValidateRequest(&KBARequest{Answers: []KBAQuestionAnswer{
+		{QuestionID: "2355953375", AnswerID: "2687969345"},
+		{QuestionID: "2355953375", AnswerID: "2687969315"},
+		{QuestionID: "1", AnswerID: "2687969385"},
+	}})
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/answers/0/answerId", "/answers/1/questionId", "/answers/2/questionId", "/answers"})
+
+	err = kba.ValidateRequest(kba.NewRequest(map[string]string{"2355953375": "2687969315"}))
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/answers/1/answerId"})
+}
+
+func TestKBAVerify(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testKBA:  {200, filepath.Join("testdata", "kba.json")},
+		"POST " + testKBA: {200, filepath.Join("testdata", "kba-verified.json")},
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F": {200, filepath.Join("testdata", "customer-verified.json")},
+	})
+
+	kba, err := c.KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+	assert.Nil(t, err)
+
+	res, err := kba.Verify(ctx, kba.NewRequest(testKBAAnswers))
+
+	assert.Nil(t, err)
+	assert.True(t, res.Verified())
+	assert.Equal(t, res.CustomerStatus, CustomerStatusVerified)
+	assert.Equal(t, res.Customer.ID, "FC451A7A-AE30-4404-AB95-E3553FCD733F")
+	assert.Equal(t, countMockRequests(mc, "POST", testKBA), 1)
+}
+
+func TestKBAVerifyError(t *testing.T) {
+	c := newMockClient(403, filepath.Join("testdata", "kba-expired.json"))
+
+	res, err := (&KBA{Resource: Resource{client: c}}).Verify(ctx, &KBARequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	kba, err := newMockClient(200, filepath.Join("testdata", "kba.json")).KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+	assert.Nil(t, err)
+
+	kba.client = c
+	res, err = kba.Verify(ctx, &KBARequest{})
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Nil(t, res)
+
+	res, err = kba.Verify(ctx, kba.NewRequest(testKBAAnswers))
+
+	assert.Equal(t, err, ErrKBAExpired)
+	assert.Nil(t, res)
+
+	// Validation can be disabled to let dwolla report invalid answers.
+	c = newMockClient(400, filepath.Join("testdata", "validation-error.json"))
+	c.DisableValidation = true
+	kba.client = c
+
+	_, err = kba.Verify(ctx, &KBARequest{})
+
+	assert.IsType(t, ValidationError{}, err)
+}
+
+func TestKBAAnswer(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testKBA:  {200, filepath.Join("testdata", "kba.json")},
+		"POST " + testKBA: {200, filepath.Join("testdata", "kba-verified.json")},
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F": {200, filepath.Join("testdata", "customer-verified.json")},
+	})
+
+	kba, err := c.KBA.Retrieve(ctx, "33aa88b1-97df-424d-9043-d5f85809858b")
+	assert.Nil(t, err)
+
+	var asked []int
+
+	res, err := kba.Answer(ctx, KBAQuestionPrompterFunc(func(ctx context.Context, index int, question KBAQuestion) (string, error) {
+		asked = append(asked, index)
+		return testKBAAnswers[question.ID], nil
+	}))
+
+	assert.Nil(t, err)
+	assert.True(t, res.Verified())
+	assert.Equal(t, asked, []int{0, 1})
+
+	res, err = kba.Answer(ctx, KBAPrompterFunc(func(ctx context.Context, questions []KBAQuestion) (map[string]string, error) {
+		return nil, errors.New("cancelled")
+	}))
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	assert.Equal(t, countMockRequests(mc, "POST", testKBA), 1)
+}
+
+func TestKBAExpired(t *testing.T) {
+	assert.True(t, kbaExpired(HALError{Code: "InvalidResourceState", Message: "The kba session has expired."}))
+
+	for _, err := range []error{
+		nil,
+		errors.New("kba session expired"),
+		HALError{Code: "ExpiredAccessToken", Message: "Access token has expired."},
+		HALError{Code: "Forbidden", Message: "The kba session has expired."},
+		HALError{Code: "NotFound", Message: "The requested resource was not found."},
+		HALError{Code: "InvalidResourceState", Message: "The kba session has already been answered."},
+		ValidationError{Code: "ValidationError", Message: "Expired answers."},
+	} {
+		assert.False(t, kbaExpired(err), "%v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// LabelService is the label service interface
+//
+// see: https://docsv2.dwolla.com/#labels
+type LabelService interface {
+	Create(context.Context, string, *LabelRequest) (*Label, error)
+	CreateLedgerEntry(context.Context, string, *LabelLedgerEntryRequest) (*LabelLedgerEntry, error)
+	CreateReallocation(context.Context, *LabelReallocationRequest) (*LabelReallocation, error)
+	List(context.Context, string, *url.Values) (*Labels, error)
+	ListLedgerEntries(context.Context, string, *url.Values) (*LabelLedgerEntries, error)
+	Remove(context.Context, string) error
+	Retrieve(context.Context, string) (*Label, error)
+	RetrieveLedgerEntry(context.Context, string) (*LabelLedgerEntry, error)
+	RetrieveReallocation(context.Context, string) (*LabelReallocation, error)
+}
+
+// LabelServiceOp is an implementation of the label service interface
+type LabelServiceOp struct {
+	client *Client
+}
+
+// Label is a dwolla label earmarking part of a customer's balance
+type Label struct {
+	Resource
+	ID      string `json:"id"`
+	Created string `json:"created"`
+	Amount  Amount `json:"amount"`
+}
+
+// Labels is a collection of labels
+type Labels struct {
+	Collection
+	Embedded map[string][]Label `json:"_embedded"`
+}
+
+// LabelRequest is a label create request
+type LabelRequest struct {
+	Amount Amount `json:"amount"`
+}
+
+// LabelLedgerEntry is an entry adjusting a label's amount
+type LabelLedgerEntry struct {
+	Resource
+	ID      string `json:"id"`
+	Created string `json:"created"`
+	Amount  Amount `json:"amount"`
+}
+
+// LabelLedgerEntries is a collection of label ledger entries
+type LabelLedgerEntries struct {
+	Collection
+	Embedded map[string][]LabelLedgerEntry `json:"_embedded"`
+}
+
+// LabelLedgerEntryRequest is a label ledger entry create request
+//
+// A negative amount decreases the label's amount.
+type LabelLedgerEntryRequest struct {
+	Amount Amount `json:"amount"`
+}
+
+// LabelReallocation is a move of funds from one label to another
+type LabelReallocation struct {
+	Resource
+	ID      string `json:"id"`
+	Created string `json:"created"`
+}
+
+// LabelReallocationRequest is a label reallocation create request
+//
+// The from and to links must point to labels of the same customer.
+type LabelReallocationRequest struct {
+	Resource
+	Amount Amount `json:"amount"`
+}
+
+// NewLabelReallocationRequest initializes a reallocation request between
+// two label hrefs
+func NewLabelReallocationRequest(from, to string, amount Amount) *LabelReallocationRequest {
+	return &LabelReallocationRequest{
+		Resource: Resource{Links: Links{"from": Link{Href: from}, "to": Link{Href: to}}},
+		Amount:   amount,
+	}
+}
+
+// Create creates a label for the customer matching the id
+//
+// see: https://docsv2.dwolla.com/#create-a-label
+func (l *LabelServiceOp) Create(ctx context.Context, customerID string, body *LabelRequest) (*Label, error) {
+	var label Label
+
+	if err := l.client.Post(ctx, fmt.Sprintf("customers/%s/labels", customerID), body, nil, &label); err != nil {
+		return nil, err
+	}
+
+	label.client = l.client
+
+	return &label, nil
+}
+
+// CreateLedgerEntry creates a ledger entry for the label matching the id
+//
+// see: https://docsv2.dwolla.com/#create-a-label-ledger-entry
+func (l *LabelServiceOp) CreateLedgerEntry(ctx context.Context, labelID string, body *LabelLedgerEntryRequest) (*LabelLedgerEntry, error) {
+	var entry LabelLedgerEntry
+
+	if err := l.client.Post(ctx, fmt.Sprintf("labels/%s/ledger-entries", labelID), body, nil, &entry); err != nil {
+		return nil, err
+	}
+
+	entry.client = l.client
+
+	return &entry, nil
+}
+
+// CreateReallocation moves an amount from one label to another
+//
+// see: https://docsv2.dwolla.com/#create-a-label-reallocation
+func (l *LabelServiceOp) CreateReallocation(ctx context.Context, body *LabelReallocationRequest) (*LabelReallocation, error) {
+	var reallocation LabelReallocation
+
+	if err := l.client.Post(ctx, "label-reallocations", body, nil, &reallocation); err != nil {
+		return nil, err
+	}
+
+	reallocation.client = l.client
+
+	return &reallocation, nil
+}
+
+// List returns the labels for the customer matching the id
+//
+// see: https://docsv2.dwolla.com/#list-labels-for-a-customer
+func (l *LabelServiceOp) List(ctx context.Context, customerID string, params *url.Values) (*Labels, error) {
+	var labels Labels
+
+	if err := l.client.Get(ctx, fmt.Sprintf("customers/%s/labels", customerID), params, nil, &labels); err != nil {
+		return nil, err
+	}
+
+	labels.client = l.client
+
+	for i := range labels.Embedded["labels"] {
+		labels.Embedded["labels"][i].client = l.client
+	}
+
+	return &labels, nil
+}
+
+// ListLedgerEntries returns the ledger entries for the label matching the id
+//
+// see: https://docsv2.dwolla.com/#list-label-ledger-entries
+func (l *LabelServiceOp) ListLedgerEntries(ctx context.Context, labelID string, params *url.Values) (*LabelLedgerEntries, error) {
+	var entries LabelLedgerEntries
+
+	if err := l.client.Get(ctx, fmt.Sprintf("labels/%s/ledger-entries", labelID), params, nil, &entries); err != nil {
+		return nil, err
+	}
+
+	entries.client = l.client
+
+	for i := range entries.Embedded["ledger-entries"] {
+		entries.Embedded["ledger-entries"][i].client = l.client
+	}
+
+	return &entries, nil
+}
+
+// Remove removes the label matching the id
+//
+// see: https://docsv2.dwolla.com/#remove-a-label
+func (l *LabelServiceOp) Remove(ctx context.Context, id string) error {
+	return l.client.Delete(ctx, fmt.Sprintf("labels/%s", id), nil, nil)
+}
+
+// Retrieve retrieves the label matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-a-label
+func (l *LabelServiceOp) Retrieve(ctx context.Context, id string) (*Label, error) {
+	var label Label
+
+	if err := l.client.Get(ctx, fmt.Sprintf("labels/%s", id), nil, nil, &label); err != nil {
+		return nil, err
+	}
+
+	label.client = l.client
+
+	return &label, nil
+}
+
+// RetrieveLedgerEntry retrieves the label ledger entry matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-a-label-ledger-entry
+func (l *LabelServiceOp) RetrieveLedgerEntry(ctx context.Context, id string) (*LabelLedgerEntry, error) {
+	var entry LabelLedgerEntry
+
+	if err := l.client.Get(ctx, fmt.Sprintf("ledger-entries/%s", id), nil, nil, &entry); err != nil {
+		return nil, err
+	}
+
+	entry.client = l.client
+
+	return &entry, nil
+}
+
+// RetrieveReallocation retrieves the label reallocation matching the id
+//
+// see: https://docsv2.dwolla.com/#retrieve-a-label-reallocation
+func (l *LabelServiceOp) RetrieveReallocation(ctx context.Context, id string) (*LabelReallocation, error) {
+	var reallocation LabelReallocation
+
+	if err := l.client.Get(ctx, fmt.Sprintf("label-reallocations/%s", id), nil, nil, &reallocation); err != nil {
+		return nil, err
+	}
+
+	reallocation.client = l.client
+
+	return &reallocation, nil
+}
+
+// CreateLedgerEntry creates a ledger entry for the label
+//
+// see: https://docsv2.dwolla.com/#create-a-label-ledger-entry
+func (l *Label) CreateLedgerEntry(ctx context.Context, body *LabelLedgerEntryRequest) (*LabelLedgerEntry, error) {
+	var entry LabelLedgerEntry
+
+	if _, ok := l.Links["ledger-entries"]; !ok {
+		return nil, errors.New("No ledger entries resource link")
+	}
+
+	if err := l.client.Post(ctx, l.Links["ledger-entries"].Href, body, nil, &entry); err != nil {
+		return nil, err
+	}
+
+	entry.client = l.client
+
+	return &entry, nil
+}
+
+// ListLedgerEntries returns the label's ledger entries
+//
+// see: https://docsv2.dwolla.com/#list-label-ledger-entries
+func (l *Label) ListLedgerEntries(ctx context.Context, params *url.Values) (*LabelLedgerEntries, error) {
+	var entries LabelLedgerEntries
+
+	if _, ok := l.Links["ledger-entries"]; !ok {
+		return nil, errors.New("No ledger entries resource link")
+	}
+
+	if err := l.client.Get(ctx, l.Links["ledger-entries"].Href, params, nil, &entries); err != nil {
+		return nil, err
+	}
+
+	entries.client = l.client
+
+	for i := range entries.Embedded["ledger-entries"] {
+		entries.Embedded["ledger-entries"][i].client = l.client
+	}
+
+	return &entries, nil
+}
+
+// Reallocate moves an amount from the label to another label
+//
+// see: https://docsv2.dwolla.com/#create-a-label-reallocation
+func (l *Label) Reallocate(ctx context.Context, to *Label, amount Amount) (*LabelReallocation, error) {
+	if _, ok := l.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	if to == nil {
+		return nil, errors.New("No destination label")
+	}
+
+	if _, ok := to.Links["self"]; !ok {
+		return nil, errors.New("No self resource link on destination label")
+	}
+
+	return l.client.Label.CreateReallocation(ctx, NewLabelReallocationRequest(l.Links["self"].Href, to.Links["self"].Href, amount))
+}
+
+// Remove removes the label
+//
+// see: https://docsv2.dwolla.com/#remove-a-label
+func (l *Label) Remove(ctx context.Context) error {
+	if _, ok := l.Links["remove"]; !ok {
+		return errors.New("No remove resource link")
+	}
+
+	return l.client.Delete(ctx, l.Links["remove"].Href, nil, nil)
+}
+
+// RetrieveLabel retrieves the label the ledger entry belongs to
+func (e *LabelLedgerEntry) RetrieveLabel(ctx context.Context) (*Label, error) {
+	var label Label
+
+	if _, ok := e.Links["label"]; !ok {
+		return nil, errors.New("No label resource link")
+	}
+
+	if err := e.client.Get(ctx, e.Links["label"].Href, nil, nil, &label); err != nil {
+		return nil, err
+	}
+
+	label.client = e.client
+
+	return &label, nil
+}
@@ -0,0 +1,245 @@
+package dwolla
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelServiceCreate(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "label.json"))
+	res, err := c.Label.Create(ctx, "315a9456-3750-44bf-8b41-487b10d1d4bb", &LabelRequest{Amount: Amount{Value: "10.00", Currency: USD}})
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.ID, "7e042ffe-e25e-40d2-b86e-748b98845ecc")
+	assert.Equal(t, res.Amount.Value, "10.00")
+}
+
+func TestLabelServiceCreateError(t *testing.T) {
+	c := newMockClient(400, filepath.Join("testdata", "validation-error.json"))
+	res, err := c.Label.Create(ctx, "315a9456-3750-44bf-8b41-487b10d1d4bb", &LabelRequest{})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestLabelServiceList(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "labels.json"))
+	res, err := c.Label.List(ctx, "315a9456-3750-44bf-8b41-487b10d1d4bb", nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.Total, 2)
+	assert.Len(t, res.Embedded["labels"], 2)
+	assert.Equal(t, res.Embedded["labels"][1].Amount.Value, "25.50")
+}
+
+func TestLabelServiceListError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+	res, err := c.Label.List(ctx, "315a9456-3750-44bf-8b41-487b10d1d4bb", nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestLabelServiceRetrieve(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "label.json"))
+	res, err := c.Label.Retrieve(ctx, "7e042ffe-e25e-40d2-b86e-748b98845ecc")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.Created, "2019-05-15T22:19:09.635Z")
+}
+
+func TestLabelServiceRemove(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "label.json"))
+	err := c.Label.Remove(ctx, "7e042ffe-e25e-40d2-b86e-748b98845ecc")
+
+	assert.Nil(t, err)
+}
+
+func TestLabelServiceLedgerEntries(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "label-ledger-entry.json"))
+	entry, err := c.Label.CreateLedgerEntry(ctx, "7e042ffe-e25e-40d2-b86e-748b98845ecc", &LabelLedgerEntryRequest{Amount: Amount{Value: "-5.00", Currency: USD}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, entry.ID, "32d68709-62dd-43d6-a6df-562f4baec526")
+	assert.Equal(t, entry.Amount.Value, "-5.00")
+
+	c = newMockClient(200, filepath.Join("testdata", "label-ledger-entry.json"))
+	entry, err = c.Label.RetrieveLedgerEntry(ctx, "32d68709-62dd-43d6-a6df-562f4baec526")
+
+	assert.Nil(t, err)
+	assert.Equal(t, entry.Links["label"].Href, "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc")
+
+	c = newMockClient(200, filepath.Join("testdata", "label-ledger-entries.json"))
+	entries, err := c.Label.ListLedgerEntries(ctx, "7e042ffe-e25e-40d2-b86e-748b98845ecc", nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, entries.Embedded["ledger-entries"], 2)
+	assert.Equal(t, entries.Embedded["ledger-entries"][1].Amount.Value, "15.00")
+}
+
+func TestLabelServiceReallocations(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "label-reallocation.json"))
+	req := NewLabelReallocationRequest(
+		"https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc",
+		"https://api-sandbox.dwolla.com/labels/c2a8e0a1-5e3b-4f58-9a21-5c0a6e1b7d44",
+		Amount{Value: "5.00", Currency: USD},
+	)
+	res, err := c.Label.CreateReallocation(ctx, req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "fd36b78f-0b6a-4b3a-9c4c-1d8f3d3e2b6e")
+	assert.Contains(t, res.Links, "from-ledger-entry")
+
+	c = newMockClient(200, filepath.Join("testdata", "label-reallocation.json"))
+	res, err = c.Label.RetrieveReallocation(ctx, "fd36b78f-0b6a-4b3a-9c4c-1d8f3d3e2b6e")
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Created, "2019-05-16T18:42:01.000Z")
+}
+
+func TestLabelReallocationRequestMarshal(t *testing.T) {
+	req := NewLabelReallocationRequest("from", "to", Amount{Value: "5.00", Currency: USD})
+	data, err := json.Marshal(req)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(data), `{"_links":{"from":{"href":"from"},"to":{"href":"to"}},"amount":{"value":"5.00","currency":"usd"}}`)
+}
+
+func TestLabelCreateLedgerEntry(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "label-ledger-entry.json"))
+
+	label := &Label{Resource: Resource{client: c, Links: Links{"ledger-entries": Link{Href: "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc/ledger-entries"}}}}
+	entry, err := label.CreateLedgerEntry(ctx, &LabelLedgerEntryRequest{Amount: Amount{Value: "-5.00", Currency: USD}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, entry.ID, "32d68709-62dd-43d6-a6df-562f4baec526")
+
+	c = newMockClient(200, filepath.Join("testdata", "label.json"))
+	entry.client = c
+	res, err := entry.RetrieveLabel(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "7e042ffe-e25e-40d2-b86e-748b98845ecc")
+}
+
+func TestLabelCreateLedgerEntryError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	label := &Label{Resource: Resource{client: c}}
+	res, err := label.CreateLedgerEntry(ctx, &LabelLedgerEntryRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), "No ledger entries resource link")
+	assert.Nil(t, res)
+
+	entries, err := label.ListLedgerEntries(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, entries)
+
+	_, err = (&LabelLedgerEntry{Resource: Resource{client: c}}).RetrieveLabel(ctx)
+
+	assert.Error(t, err)
+}
+
+func TestLabelListLedgerEntries(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "label-ledger-entries.json"))
+
+	label := &Label{Resource: Resource{client: c, Links: Links{"ledger-entries": Link{Href: "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc/ledger-entries"}}}}
+	res, err := label.ListLedgerEntries(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Total, 2)
+}
+
+func TestLabelReallocate(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /label-reallocations": {201, filepath.Join("testdata", "label-reallocation.json")},
+	})
+
+	from := &Label{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc"}}}}
+	to := &Label{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/labels/c2a8e0a1-5e3b-4f58-9a21-5c0a6e1b7d44"}}}}
+	res, err := from.Reallocate(ctx, to, Amount{Value: "5.00", Currency: USD})
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "fd36b78f-0b6a-4b3a-9c4c-1d8f3d3e2b6e")
+
+	body, _ := ioutil.ReadAll(mc.requestLog()[0].Body)
+
+	var req LabelReallocationRequest
+
+	assert.Nil(t, json.Unmarshal(body, &req))
+	assert.Equal(t, req.Links["from"].Href, from.Links["self"].Href)
+	assert.Equal(t, req.Links["to"].Href, to.Links["self"].Href)
+
+	_, err = from.Reallocate(ctx, &Label{}, Amount{Value: "5.00", Currency: USD})
+
+	assert.Error(t, err)
+	_, err = from.Reallocate(ctx, nil, Amount{Value: "5.00", Currency: USD})
+
+	assert.Error(t, err)
+}
+
+func TestLabelRemove(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "label.json"))
+
+	label := &Label{Resource: Resource{client: c}}
+
+	assert.Error(t, label.Remove(ctx))
+
+	label.Links = Links{"remove": Link{Href: "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc"}}
+
+	assert.Nil(t, label.Remove(ctx))
+}
+
+func TestCustomerCreateLabel(t *testing.T) {
+	c := newMockClient(201, filepath.Join("testdata", "label.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.CreateLabel(ctx, &LabelRequest{Amount: Amount{Value: "10.00", Currency: USD}})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/315a9456-3750-44bf-8b41-487b10d1d4bb"}}
+	res, err = customer.CreateLabel(ctx, &LabelRequest{Amount: Amount{Value: "10.00", Currency: USD}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.ID, "7e042ffe-e25e-40d2-b86e-748b98845ecc")
+}
+
+func TestCustomerListLabels(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "labels.json"))
+
+	customer := &Customer{Resource: Resource{client: c}}
+	res, err := customer.ListLabels(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	customer.Links = Links{"self": Link{Href: "https://api-sandbox.dwolla.com/customers/315a9456-3750-44bf-8b41-487b10d1d4bb"}}
+	res, err = customer.ListLabels(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Embedded["labels"], 2)
+}
+
+func TestEventRetrieveLabel(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "label.json"))
+
+	event := &Event{
+		Resource: Resource{client: c, Links: Links{"resource": Link{Href: "https://api-sandbox.dwolla.com/labels/7e042ffe-e25e-40d2-b86e-748b98845ecc"}}},
+		Topic:    EventTopicCustomerLabelCreated,
+	}
+	res, err := event.RetrieveResource(ctx)
+
+	assert.Nil(t, err)
+	assert.IsType(t, &Label{}, res)
+}
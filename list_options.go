@@ -0,0 +1,165 @@
+package dwolla
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListDateFormat is the date format used by list and search filters
+const ListDateFormat = "2006-01-02"
+
+// ListOptions are the paging options shared by list requests
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// encode adds the paging options to the query values
+func (o ListOptions) encode(params *url.Values) {
+	if o.Limit > 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+	}
+
+	if o.Offset > 0 {
+		params.Set("offset", strconv.Itoa(o.Offset))
+	}
+}
+
+// CustomerListOptions are the filters for listing and searching customers
+//
+// see: https://docsv2.dwolla.com/#list-and-search-customers
+type CustomerListOptions struct {
+	ListOptions
+	Search string
+	Email  string
+	Status []CustomerStatus
+}
+
+// Values encodes the options as query values for CustomerService.List
+func (o *CustomerListOptions) Values() *url.Values {
+	if o == nil {
+		return nil
+	}
+
+	params := &url.Values{}
+
+	o.ListOptions.encode(params)
+
+	if o.Search != "" {
+		params.Set("search", o.Search)
+	}
+
+	if o.Email != "" {
+		params.Set("email", o.Email)
+	}
+
+	for _, status := range o.Status {
+		params.Add("status", string(status))
+	}
+
+	return params
+}
+
+// TransferSearchOptions are the filters for listing and searching
+// transfers
+//
+// see: https://docsv2.dwolla.com/#list-and-search-transfers-for-a-customer
+type TransferSearchOptions struct {
+	ListOptions
+	Search        string
+	StartAmount   Amount
+	EndAmount     Amount
+	StartDate     time.Time
+	EndDate       time.Time
+	Status        []TransferStatus
+	CorrelationID string
+}
+
+// Values encodes the options as query values for Customer.ListTransfers and
+// Account.ListTransfers
+func (o *TransferSearchOptions) Values() *url.Values {
+	if o == nil {
+		return nil
+	}
+
+	params := &url.Values{}
+
+	o.ListOptions.encode(params)
+
+	if o.Search != "" {
+		params.Set("search", o.Search)
+	}
+
+	if o.StartAmount.Value != "" {
+		params.Set("startAmount", o.StartAmount.Value)
+	}
+
+	if o.EndAmount.Value != "" {
+		params.Set("endAmount", o.EndAmount.Value)
+	}
+
+	if !o.StartDate.IsZero() {
+		params.Set("startDate", o.StartDate.Format(ListDateFormat))
+	}
+
+	if !o.EndDate.IsZero() {
+		params.Set("endDate", o.EndDate.Format(ListDateFormat))
+	}
+
+	for _, status := range o.Status {
+		params.Add("status", string(status))
+	}
+
+	if o.CorrelationID != "" {
+		params.Set("correlationId", o.CorrelationID)
+	}
+
+	return params
+}
+
+// EventListOptions are the filters for listing events
+//
+// see: https://docsv2.dwolla.com/#list-events
+type EventListOptions struct {
+	ListOptions
+}
+
+// Values encodes the options as query values for EventService.List
+func (o *EventListOptions) Values() *url.Values {
+	if o == nil {
+		return nil
+	}
+
+	params := &url.Values{}
+
+	o.ListOptions.encode(params)
+
+	return params
+}
+
+// MassPaymentListOptions are the filters for listing mass payments
+//
+// see: https://docsv2.dwolla.com/#list-mass-payments-for-an-account
+type MassPaymentListOptions struct {
+	ListOptions
+	CorrelationID string
+}
+
+// Values encodes the options as query values for Account.ListMassPayments
+// and Customer.ListMassPayments
+func (o *MassPaymentListOptions) Values() *url.Values {
+	if o == nil {
+		return nil
+	}
+
+	params := &url.Values{}
+
+	o.ListOptions.encode(params)
+
+	if o.CorrelationID != "" {
+		params.Set("correlationId", o.CorrelationID)
+	}
+
+	return params
+}
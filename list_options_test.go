@@ -0,0 +1,63 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerListOptionsValues(t *testing.T) {
+	var opts *CustomerListOptions
+	assert.Nil(t, opts.Values())
+
+	opts = &CustomerListOptions{
+		ListOptions: ListOptions{Limit: 10, Offset: 20},
+		Search:      "Jane",
+		Email:       "janedoe@nomail.com",
+		Status:      []CustomerStatus{CustomerStatusVerified, CustomerStatusDocument},
+	}
+
+	assert.Equal(t, opts.Values().Encode(), "email=janedoe%40nomail.com&limit=10&offset=20&search=Jane&status=verified&status=document")
+}
+
+func TestTransferSearchOptionsValues(t *testing.T) {
+	opts := &TransferSearchOptions{
+		StartAmount:   Amount{Value: "10.00", Currency: USD},
+		EndAmount:     Amount{Value: "100.00", Currency: USD},
+		StartDate:     time.Date(2015, 12, 22, 10, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC),
+		Status:        []TransferStatus{TransferStatusPending},
+		CorrelationID: "8a2cdc8d-629d-4a24-98ac-40b735229fe2",
+	}
+
+	assert.Equal(t, opts.Values().Encode(), "correlationId=8a2cdc8d-629d-4a24-98ac-40b735229fe2&endAmount=100.00&endDate=2016-01-05&startAmount=10.00&startDate=2015-12-22&status=pending")
+	assert.Equal(t, (&TransferSearchOptions{}).Values().Encode(), "")
+}
+
+func TestEventListOptionsValues(t *testing.T) {
+	opts := &EventListOptions{ListOptions{Limit: 25}}
+
+	assert.Equal(t, opts.Values().Encode(), "limit=25")
+}
+
+func TestMassPaymentListOptionsValues(t *testing.T) {
+	opts := &MassPaymentListOptions{ListOptions: ListOptions{Offset: 5}, CorrelationID: "abc"}
+
+	assert.Equal(t, opts.Values().Encode(), "correlationId=abc&offset=5")
+}
+
+func TestCustomerListTransfersWithOptions(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/transfers": {200, filepath.Join("testdata", "transfers.json")},
+	})
+
+	customer := &Customer{Resource: Resource{client: c, Links: Links{"transfers": Link{Href: "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F/transfers"}}}}
+	opts := &TransferSearchOptions{Search: "Jane"}
+	res, err := customer.ListTransfers(ctx, opts.Values())
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, mc.requestLog()[0].URL.RawQuery, "search=Jane")
+}
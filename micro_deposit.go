@@ -1,6 +1,24 @@
 package dwolla
 
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinMicroDepositCents is the smallest micro deposit amount, in cents
+	MinMicroDepositCents = 1
+	// MaxMicroDepositCents is the largest micro deposit amount, in cents
+	MaxMicroDepositCents = 10
+)
+
+var amountRegexp = regexp.MustCompile(`^\d+(\.\d{1,2})?$`)
+
 const (
+	// MicroDepositStatusFailed is when the micro deposit failed to reach the
+	// bank
+	MicroDepositStatusFailed MicroDepositStatus = "failed"
 	// MicroDepositStatusPending is when the micro deposit is pending
 	// processing
 	MicroDepositStatusPending MicroDepositStatus = "pending"
@@ -30,3 +48,64 @@ type MicroDepositRequest struct {
 
 // MicroDepositStatus is the status of the micro deposit
 type MicroDepositStatus string
+
+// Validate checks that both amounts are between $0.01 and $0.10 in USD
+func (m *MicroDepositRequest) Validate() error {
+	var errs validationErrors
+
+	validateMicroDepositAmount(&errs, "/amount1", m.Amount1)
+	validateMicroDepositAmount(&errs, "/amount2", m.Amount2)
+
+	return errs.err()
+}
+
+// validateMicroDepositAmount records an error if the amount is not a USD
+// amount between $0.01 and $0.10
+func validateMicroDepositAmount(errs *validationErrors, path string, amount Amount) {
+	if amount.Value == "" {
+		errs.add("Required", path+"/value", "Amount required.")
+		return
+	}
+
+	if !strings.EqualFold(string(amount.Currency), string(USD)) {
+		errs.add("Invalid", path+"/currency", "Currency must be USD.")
+	}
+
+	cents, ok := parseCents(amount.Value)
+	if !ok {
+		errs.add("InvalidFormat", path+"/value", "Amount must be a dollar amount such as 0.05.")
+		return
+	}
+
+	if cents < MinMicroDepositCents || cents > MaxMicroDepositCents {
+		errs.add("Invalid", path+"/value", "Amount must be between 0.01 and 0.10.")
+	}
+}
+
+// parseCents parses a dollar amount with at most two decimal places into
+// cents
+func parseCents(value string) (int64, bool) {
+	if !amountRegexp.MatchString(value) {
+		return 0, false
+	}
+
+	parts := strings.SplitN(value, ".", 2)
+
+	dollars, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	var cents int64
+
+	if len(parts) == 2 {
+		fraction := parts[1]
+		if len(fraction) == 1 {
+			fraction += "0"
+		}
+
+		cents, _ = strconv.ParseInt(fraction, 10, 64)
+	}
+
+	return dollars*100 + cents, true
+}
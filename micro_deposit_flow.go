@@ -0,0 +1,362 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxMicroDepositAttempts is the number of times micro deposit amounts can
+// be submitted before dwolla stops accepting them
+const MaxMicroDepositAttempts = 3
+
+// DefaultMicroDepositInterval is the polling interval used by Wait when
+// none is given
+const DefaultMicroDepositInterval = time.Minute
+
+const (
+	// MicroDepositFlowNotStarted is when micro deposits have not been
+	// initiated
+	MicroDepositFlowNotStarted MicroDepositFlowState = "not-started"
+	// MicroDepositFlowPending is when micro deposits were initiated but have
+	// not yet reached the bank
+	MicroDepositFlowPending MicroDepositFlowState = "pending"
+	// MicroDepositFlowReady is when micro deposits have been processed and
+	// the amounts can be verified
+	MicroDepositFlowReady MicroDepositFlowState = "ready"
+	// MicroDepositFlowVerified is when the funding source is verified
+	MicroDepositFlowVerified MicroDepositFlowState = "verified"
+	// MicroDepositFlowFailed is when the micro deposits failed to reach the
+	// bank
+	MicroDepositFlowFailed MicroDepositFlowState = "failed"
+	// MicroDepositFlowMaxAttempts is when too many wrong amounts were
+	// submitted
+	MicroDepositFlowMaxAttempts MicroDepositFlowState = "max-attempts"
+)
+
+const (
+	// MicroDepositOutcomeVerified is when the amounts were correct
+	MicroDepositOutcomeVerified MicroDepositOutcome = "verified"
+	// MicroDepositOutcomeWrongAmounts is when the amounts were wrong and
+	// attempts remain
+	MicroDepositOutcomeWrongAmounts MicroDepositOutcome = "wrong-amounts"
+	// MicroDepositOutcomeMaxAttempts is when no attempts remain
+	MicroDepositOutcomeMaxAttempts MicroDepositOutcome = "max-attempts"
+	// MicroDepositOutcomeFailed is when the micro deposits failed
+	MicroDepositOutcomeFailed MicroDepositOutcome = "failed"
+)
+
+// SandboxMicroDepositAmounts are the amounts used to verify micro deposits
+// in the sandbox, which accepts any amounts up to $0.10
+var SandboxMicroDepositAmounts = MicroDepositRequest{
+	Amount1: Amount{Value: "0.03", Currency: USD},
+	Amount2: Amount{Value: "0.09", Currency: USD},
+}
+
+// MicroDepositFlowState is a step in micro deposit verification
+type MicroDepositFlowState string
+
+// MicroDepositOutcome is the outcome of a micro deposit verification
+type MicroDepositOutcome string
+
+// MicroDepositResult is the outcome of submitting micro deposit amounts
+//
+// Failure is set when the outcome is MicroDepositOutcomeFailed.
+type MicroDepositResult struct {
+	Outcome           MicroDepositOutcome
+	AttemptsRemaining int
+	Failure           MicroDepositFailure
+}
+
+// MicroDepositFlow tracks the verification of a funding source by micro
+// deposits, from initiation to verification
+//
+// Attempts counts the wrong amounts submitted through the flow. Persist it
+// with State to resume the flow later.
+type MicroDepositFlow struct {
+	FundingSource *FundingSource
+	State         MicroDepositFlowState
+	Attempts      int
+	Failure       MicroDepositFailure
+}
+
+// NewMicroDepositFlow initializes a micro deposit flow for the funding
+// source, with the state its links describe
+//
+// A flow with pending micro deposits should be refreshed to find out
+// whether they have been processed.
+func NewMicroDepositFlow(source *FundingSource) *MicroDepositFlow {
+	m := &MicroDepositFlow{FundingSource: source}
+	m.State = m.linkState()
+
+	return m
+}
+
+// linkState returns the state described by the funding source
+func (m *MicroDepositFlow) linkState() MicroDepositFlowState {
+	switch {
+	case m.FundingSource.Status == FundingSourceStatusVerified:
+		return MicroDepositFlowVerified
+	case m.FundingSource.FailedVerificationMicroDeposits():
+		return MicroDepositFlowMaxAttempts
+	}
+
+	if _, ok := m.FundingSource.Links["verify-micro-deposits"]; ok {
+		return MicroDepositFlowPending
+	}
+
+	return MicroDepositFlowNotStarted
+}
+
+// AttemptsRemaining returns the number of times amounts can still be
+// submitted
+func (m *MicroDepositFlow) AttemptsRemaining() int {
+	if m.State == MicroDepositFlowMaxAttempts {
+		return 0
+	}
+
+	if remaining := MaxMicroDepositAttempts - m.Attempts; remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// Done returns true if the flow cannot make further progress
+func (m *MicroDepositFlow) Done() bool {
+	switch m.State {
+	case MicroDepositFlowVerified, MicroDepositFlowFailed, MicroDepositFlowMaxAttempts:
+		return true
+	}
+
+	return false
+}
+
+// Start initiates micro deposits
+//
+// Flows that were already started are left as they are.
+//
+// see: https://docsv2.dwolla.com/#initiate-micro-deposits
+func (m *MicroDepositFlow) Start(ctx context.Context) error {
+	if m.State != MicroDepositFlowNotStarted {
+		return nil
+	}
+
+	if _, err := m.FundingSource.InitiateMicroDeposits(ctx); err != nil {
+		return err
+	}
+
+	m.State = MicroDepositFlowPending
+
+	return nil
+}
+
+// Refresh retrieves the funding source and its micro deposits and updates
+// the state
+func (m *MicroDepositFlow) Refresh(ctx context.Context) error {
+	if err := m.refreshFundingSource(ctx); err != nil {
+		return err
+	}
+
+	m.State = m.linkState()
+
+	if m.State != MicroDepositFlowPending {
+		return nil
+	}
+
+	deposit, err := m.FundingSource.RetrieveMicroDeposits(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch deposit.Status {
+	case MicroDepositStatusProcessed:
+		m.State = MicroDepositFlowReady
+	case MicroDepositStatusFailed:
+		m.State = MicroDepositFlowFailed
+		m.Failure = deposit.Failure
+	}
+
+	return nil
+}
+
+// Wait blocks until the micro deposits are no longer pending, refreshing
+// every interval
+//
+// It returns the context's error if the context is done first.
+func (m *MicroDepositFlow) Wait(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultMicroDepositInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for m.State == MicroDepositFlowPending {
+		if err := m.Refresh(ctx); err != nil {
+			return err
+		}
+
+		if m.State != MicroDepositFlowPending {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// Verify submits the micro deposit amounts
+//
+// The flow must be ready, with processed micro deposits. The amounts are
+// validated before they are sent. Wrong amounts and exhausted attempts are
+// reported as outcomes rather than errors.
+//
+// see: https://docsv2.dwolla.com/#verify-micro-deposits
+func (m *MicroDepositFlow) Verify(ctx context.Context, body *MicroDepositRequest) (*MicroDepositResult, error) {
+	switch m.State {
+	case MicroDepositFlowVerified, MicroDepositFlowFailed, MicroDepositFlowMaxAttempts:
+		return m.result(), nil
+	case MicroDepositFlowNotStarted:
+		return nil, errors.New("Micro deposits have not been initiated")
+	case MicroDepositFlowPending:
+		return nil, errors.New("Micro deposits have not been processed")
+	}
+
+	if err := m.FundingSource.client.validate(body); err != nil {
+		return nil, err
+	}
+
+	err := m.FundingSource.VerifyMicroDeposits(ctx, body)
+
+	switch {
+	case err == nil:
+		m.State = MicroDepositFlowVerified
+	case microDepositMaxAttempts(err):
+		m.State = MicroDepositFlowMaxAttempts
+	case microDepositWrongAmounts(err):
+		m.Attempts++
+
+		if m.Attempts >= MaxMicroDepositAttempts {
+			m.State = MicroDepositFlowMaxAttempts
+		} else if err := m.refreshFundingSource(ctx); err == nil && m.FundingSource.FailedVerificationMicroDeposits() {
+			m.State = MicroDepositFlowMaxAttempts
+		}
+
+		if m.State != MicroDepositFlowMaxAttempts {
+			return &MicroDepositResult{Outcome: MicroDepositOutcomeWrongAmounts, AttemptsRemaining: m.AttemptsRemaining()}, nil
+		}
+	default:
+		return nil, err
+	}
+
+	if m.State == MicroDepositFlowVerified {
+		if err := m.refreshFundingSource(ctx); err != nil {
+			return m.result(), err
+		}
+	}
+
+	return m.result(), nil
+}
+
+// Run initiates micro deposits if needed and waits for them to be
+// processed
+//
+// In the sandbox the amounts are then verified with
+// SandboxMicroDepositAmounts, so the flow completes on its own. Otherwise
+// a nil result is returned once the flow is ready for the amounts the
+// customer sees on their statement.
+func (m *MicroDepositFlow) Run(ctx context.Context, interval time.Duration) (*MicroDepositResult, error) {
+	if err := m.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := m.Wait(ctx, interval); err != nil {
+		return nil, err
+	}
+
+	if m.Done() {
+		return m.result(), nil
+	}
+
+	if m.FundingSource.client.Environment != Sandbox {
+		return nil, nil
+	}
+
+	amounts := SandboxMicroDepositAmounts
+
+	return m.Verify(ctx, &amounts)
+}
+
+// result returns the outcome of a finished flow
+func (m *MicroDepositFlow) result() *MicroDepositResult {
+	result := &MicroDepositResult{AttemptsRemaining: m.AttemptsRemaining()}
+
+	switch m.State {
+	case MicroDepositFlowVerified:
+		result.Outcome = MicroDepositOutcomeVerified
+	case MicroDepositFlowFailed:
+		result.Outcome = MicroDepositOutcomeFailed
+		result.Failure = m.Failure
+	case MicroDepositFlowMaxAttempts:
+		result.Outcome = MicroDepositOutcomeMaxAttempts
+	}
+
+	return result
+}
+
+// refreshFundingSource retrieves the funding source and updates it in place
+func (m *MicroDepositFlow) refreshFundingSource(ctx context.Context) error {
+	if _, ok := m.FundingSource.Links["self"]; !ok {
+		return errors.New("No self resource link")
+	}
+
+	var source FundingSource
+
+	if err := m.FundingSource.client.Get(ctx, m.FundingSource.Links["self"].Href, nil, nil, &source); err != nil {
+		return err
+	}
+
+	source.client = m.FundingSource.client
+	*m.FundingSource = source
+
+	return nil
+}
+
+// microDepositWrongAmounts returns true if dwolla rejected the amounts
+//
+// Dwolla reports wrong amounts as a ValidationError with an InvalidAmount
+// or Invalid error on one of the amounts. Verify validates the amounts
+// before they are sent, so local validation errors never reach this check.
+func microDepositWrongAmounts(err error) bool {
+	validationError, ok := err.(ValidationError)
+	if !ok || validationError.Code != "ValidationError" {
+		return false
+	}
+
+	for _, embedded := range validationError.Embedded["errors"] {
+		if (embedded.Code == "InvalidAmount" || embedded.Code == "Invalid") && strings.HasPrefix(embedded.Path, "/amount") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// microDepositMaxAttempts returns true if dwolla no longer accepts amounts
+// for the funding source
+//
+// Dwolla reports too many attempts as an InvalidResourceState error with a
+// "Too many attempts." message. Other invalid states, such as a removed or
+// already verified funding source, are returned to the caller as errors.
+func microDepositMaxAttempts(err error) bool {
+	halError, ok := err.(HALError)
+
+	return ok && halError.Code == "InvalidResourceState" && strings.Contains(strings.ToLower(halError.Message), "too many attempts")
+}
@@ -0,0 +1,244 @@
+package dwolla
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testFundingSource = "/funding-sources/49dbaa24-1580-4b1c-8b58-24e26656fa31"
+	testMicroDeposits = testFundingSource + "/micro-deposits"
+)
+
+func TestMicroDepositRequestValidate(t *testing.T) {
+	assert.Nil(t, SandboxMicroDepositAmounts.Validate())
+
+	req := &MicroDepositRequest{
+		Amount1: Amount{Value: "0.11", Currency: USD},
+		Amount2: Amount{Value: "0.1", Currency: "USD"},
+	}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/amount1/value"})
+
+	req = &MicroDepositRequest{
+		Amount1: Amount{Value: "0.00", Currency: USD},
+		Amount2: Amount{Value: ".05", Currency: "eur"},
+	}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/amount1/value", "/amount2/currency", "/amount2/value"})
+	assert.Equal(t, validationErrorPaths((&MicroDepositRequest{}).Validate()), []string{"/amount1/value", "/amount2/value"})
+}
+
+func TestParseCents(t *testing.T) {
+	for value, expected := range map[string]int64{"0.01": 1, "0.1": 10, "1": 100, "12.34": 1234} {
+		cents, ok := parseCents(value)
+
+		assert.True(t, ok)
+		assert.Equal(t, cents, expected)
+	}
+
+	for _, value := range []string{"", ".5", "1.234", "-1.00", "1,00"} {
+		_, ok := parseCents(value)
+
+		assert.False(t, ok)
+	}
+}
+
+func TestNewMicroDepositFlow(t *testing.T) {
+	for file, state := range map[string]MicroDepositFlowState{
+		"funding-source.json":                       MicroDepositFlowNotStarted,
+		"funding-source-micro-deposits.json":        MicroDepositFlowPending,
+		"funding-source-micro-deposits-failed.json": MicroDepositFlowMaxAttempts,
+		"funding-source-verified.json":              MicroDepositFlowVerified,
+	} {
+		flow := NewMicroDepositFlow(newTestFundingSource(nil, file))
+
+		assert.Equal(t, string(flow.State), string(state), file)
+	}
+}
+
+func TestMicroDepositFlowRun(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testMicroDeposits: {201, filepath.Join("testdata", "micro-deposits-pending.json")},
+		"GET " + testFundingSource:  {200, filepath.Join("testdata", "funding-source-micro-deposits.json")},
+		"GET " + testMicroDeposits:  {200, filepath.Join("testdata", "micro-deposits-processed.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source.json"))
+
+	result, err := flow.Run(ctx, time.Millisecond)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeVerified)
+	assert.Equal(t, flow.State, MicroDepositFlowVerified)
+	assert.True(t, flow.Done())
+	assert.Equal(t, countMockRequests(mc, "POST", testMicroDeposits), 2)
+}
+
+func TestMicroDepositFlowRunProduction(t *testing.T) {
+	c, mc := newMockRoutedClient(nil)
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	flow.FundingSource.client.Environment = Production
+	flow.State = MicroDepositFlowReady
+
+	result, err := flow.Run(ctx, time.Millisecond)
+
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, flow.State, MicroDepositFlowReady)
+	assert.Equal(t, flow.AttemptsRemaining(), MaxMicroDepositAttempts)
+	assert.Len(t, mc.requestLog(), 0)
+}
+
+func TestMicroDepositFlowWait(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testFundingSource: {200, filepath.Join("testdata", "funding-source-micro-deposits.json")},
+		"GET " + testMicroDeposits: {200, filepath.Join("testdata", "micro-deposits-pending.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, flow.Wait(timeout, time.Millisecond), context.DeadlineExceeded)
+	assert.Equal(t, flow.State, MicroDepositFlowPending)
+
+	mc.routes["GET "+testMicroDeposits] = mockRoute{200, filepath.Join("testdata", "micro-deposits.json")}
+
+	assert.Nil(t, flow.Wait(ctx, time.Millisecond))
+	assert.Equal(t, flow.State, MicroDepositFlowFailed)
+
+	result, err := flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeFailed)
+	assert.Equal(t, result.Failure.Code, "R03")
+}
+
+func TestMicroDepositFlowVerifyWrongAmounts(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testFundingSource:  {200, filepath.Join("testdata", "funding-source-micro-deposits.json")},
+		"POST " + testMicroDeposits: {400, filepath.Join("testdata", "micro-deposits-wrong-amounts.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	flow.State = MicroDepositFlowReady
+
+	result, err := flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeWrongAmounts)
+	assert.Equal(t, result.AttemptsRemaining, 2)
+
+	// Dwolla reports the last failed attempt with a failed verification
+	// link on the funding source.
+	mc.routes["GET "+testFundingSource] = mockRoute{200, filepath.Join("testdata", "funding-source-micro-deposits-failed.json")}
+
+	result, err = flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeMaxAttempts)
+	assert.Equal(t, result.AttemptsRemaining, 0)
+
+	result, err = flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeMaxAttempts)
+	assert.Equal(t, countMockRequests(mc, "POST", testMicroDeposits), 2)
+}
+
+func TestMicroDepositFlowVerifyMaxAttempts(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testMicroDeposits: {403, filepath.Join("testdata", "micro-deposits-max-attempts.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	flow.State = MicroDepositFlowReady
+
+	result, err := flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Nil(t, err)
+	assert.Equal(t, result.Outcome, MicroDepositOutcomeMaxAttempts)
+}
+
+func TestMicroDepositFlowVerifyInvalidState(t *testing.T) {
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testMicroDeposits: {403, filepath.Join("testdata", "micro-deposits-invalid-state.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	flow.State = MicroDepositFlowReady
+
+	result, err := flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.IsType(t, HALError{}, err)
+	assert.Nil(t, result)
+	assert.Equal(t, flow.State, MicroDepositFlowReady)
+}
+
+func TestMicroDepositFlowVerifyError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testMicroDeposits: {404, filepath.Join("testdata", "resource-not-found.json")},
+	})
+	flow := NewMicroDepositFlow(newTestFundingSource(c, "funding-source.json"))
+
+	_, err := flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Error(t, err)
+
+	c, mc = newMockRoutedClient(mc.routes)
+	flow = NewMicroDepositFlow(newTestFundingSource(c, "funding-source-micro-deposits.json"))
+
+	_, err = flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.Error(t, err)
+
+	flow.State = MicroDepositFlowReady
+
+	_, err = flow.Verify(ctx, &MicroDepositRequest{Amount1: Amount{Value: "1.00", Currency: USD}})
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Len(t, mc.requestLog(), 0)
+
+	_, err = flow.Verify(ctx, &SandboxMicroDepositAmounts)
+
+	assert.IsType(t, HALError{}, err)
+	assert.Equal(t, flow.State, MicroDepositFlowReady)
+}
+
+func TestMicroDepositErrorClassification(t *testing.T) {
+	wrongAmounts := ValidationError{Code: "ValidationError", Embedded: HALErrors{"errors": {{Code: "InvalidAmount", Message: "Wrong amount(s).", Path: "/amount1/value"}}}}
+	maxAttempts := HALError{Code: "InvalidResourceState", Message: "Too many attempts."}
+
+	assert.True(t, microDepositWrongAmounts(wrongAmounts))
+	assert.True(t, microDepositWrongAmounts(ValidationError{Code: "ValidationError", Embedded: HALErrors{"errors": {{Code: "Invalid", Message: "Wrong amount(s).", Path: "/amount1/value"}}}}))
+	assert.True(t, microDepositMaxAttempts(maxAttempts))
+
+	for _, err := range []error{
+		nil,
+		context.Canceled,
+		maxAttempts,
+		HALError{Code: "InvalidAmount", Message: "Wrong amount(s)."},
+		ValidationError{Code: "ValidationError", Message: "Wrong amount(s)."},
+		ValidationError{Code: "ValidationError", Embedded: HALErrors{"errors": {{Code: "InvalidAmount", Message: "Invalid amount.", Path: "/name"}}}},
+	} {
+		assert.False(t, microDepositWrongAmounts(err), "%v", err)
+	}
+
+	for _, err := range []error{
+		nil,
+		context.Canceled,
+		wrongAmounts,
+		HALError{Code: "Forbidden", Message: "Too many attempts."},
+		HALError{Code: "NotFound", Message: "Micro-deposits not initiated."},
+		HALError{Code: "InvalidResourceState", Message: "Bank already verified."},
+		HALError{Code: "InvalidResourceState", Message: "Funding source has been removed."},
+		ValidationError{Code: "ValidationError", Message: "Too many attempts."},
+	} {
+		assert.False(t, microDepositMaxAttempts(err), "%v", err)
+	}
+}
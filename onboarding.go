@@ -0,0 +1,621 @@
+package dwolla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OnboardingStepCreateCustomer is when the customer has not been created
+	OnboardingStepCreateCustomer OnboardingStep = "create-customer"
+	// OnboardingStepCustomerVerification is when the customer is waiting on
+	// verification, such as a document review
+	OnboardingStepCustomerVerification OnboardingStep = "customer-verification"
+	// OnboardingStepBeneficialOwners is when beneficial owners are being
+	// added or verified
+	OnboardingStepBeneficialOwners OnboardingStep = "beneficial-owners"
+	// OnboardingStepCertifyOwnership is when beneficial ownership has not
+	// been certified
+	OnboardingStepCertifyOwnership OnboardingStep = "certify-ownership"
+	// OnboardingStepComplete is when onboarding is complete
+	OnboardingStepComplete OnboardingStep = "complete"
+)
+
+// onboardingSteps are the onboarding steps in the order they are completed
+var onboardingSteps = []OnboardingStep{
+	OnboardingStepCreateCustomer,
+	OnboardingStepCustomerVerification,
+	OnboardingStepBeneficialOwners,
+	OnboardingStepCertifyOwnership,
+	OnboardingStepComplete,
+}
+
+// OnboardingStep is a step of the customer onboarding workflow
+type OnboardingStep string
+
+// OnboardingBeneficialOwner is a beneficial owner to add during onboarding
+// along with the documents to upload if the owner needs them
+type OnboardingBeneficialOwner struct {
+	Request   *BeneficialOwnerRequest
+	Documents []*DocumentRequest
+}
+
+// OnboardingBeneficialOwnerState is the progress of a single beneficial
+// owner
+type OnboardingBeneficialOwnerState struct {
+	URL       string                `json:"url,omitempty"`
+	Status    BeneficialOwnerStatus `json:"status,omitempty"`
+	Documents []string              `json:"documents,omitempty"`
+}
+
+// OnboardingState is the persisted progress of an onboarding
+//
+// Customer and beneficial owner urls are recorded as soon as they are
+// created, and uploaded documents are recorded by position, so a resumed
+// onboarding never creates or uploads anything twice.
+type OnboardingState struct {
+	ID                string                           `json:"id"`
+	Step              OnboardingStep                   `json:"step"`
+	CustomerURL       string                           `json:"customerUrl,omitempty"`
+	CustomerStatus    CustomerStatus                   `json:"customerStatus,omitempty"`
+	CustomerDocuments []string                         `json:"customerDocuments,omitempty"`
+	BeneficialOwners  []OnboardingBeneficialOwnerState `json:"beneficialOwners,omitempty"`
+	OwnershipStatus   CertificationStatus              `json:"ownershipStatus,omitempty"`
+	Waiting           string                           `json:"waiting,omitempty"`
+	LastError         string                           `json:"lastError,omitempty"`
+	Updated           time.Time                        `json:"updated"`
+}
+
+// OnboardingProgress is a summary of an onboarding's progress
+type OnboardingProgress struct {
+	ID                       string         `json:"id"`
+	Step                     OnboardingStep `json:"step"`
+	CompletedSteps           int            `json:"completedSteps"`
+	TotalSteps               int            `json:"totalSteps"`
+	CustomerStatus           CustomerStatus `json:"customerStatus,omitempty"`
+	BeneficialOwners         int            `json:"beneficialOwners"`
+	VerifiedBeneficialOwners int            `json:"verifiedBeneficialOwners"`
+	DocumentsUploaded        int            `json:"documentsUploaded"`
+	Certified                bool           `json:"certified"`
+	Complete                 bool           `json:"complete"`
+	Waiting                  string         `json:"waiting,omitempty"`
+	LastError                string         `json:"lastError,omitempty"`
+	Updated                  time.Time      `json:"updated"`
+}
+
+// Progress summarizes the onboarding state
+func (s *OnboardingState) Progress() OnboardingProgress {
+	progress := OnboardingProgress{
+		ID:               s.ID,
+		Step:             s.Step,
+		TotalSteps:       len(onboardingSteps) - 1,
+		CustomerStatus:   s.CustomerStatus,
+		BeneficialOwners: len(s.BeneficialOwners),
+		Certified:        s.OwnershipStatus == CertificationStatusCertified,
+		Complete:         s.Step == OnboardingStepComplete,
+		Waiting:          s.Waiting,
+		LastError:        s.LastError,
+		Updated:          s.Updated,
+	}
+
+	for i, step := range onboardingSteps {
+		if step == s.Step {
+			progress.CompletedSteps = i
+		}
+	}
+
+	progress.DocumentsUploaded = len(s.CustomerDocuments)
+
+	for _, owner := range s.BeneficialOwners {
+		if owner.Status == BeneficialOwnerStatusVerified {
+			progress.VerifiedBeneficialOwners++
+		}
+
+		progress.DocumentsUploaded += len(owner.Documents)
+	}
+
+	return progress
+}
+
+// OnboardingStateStore persists onboarding state
+//
+// Load returns nil when no state has been saved for the id.
+type OnboardingStateStore interface {
+	Load(context.Context, string) (*OnboardingState, error)
+	Save(context.Context, *OnboardingState) error
+}
+
+// MemoryOnboardingStateStore is an in-memory onboarding state store
+type MemoryOnboardingStateStore struct {
+	mu     sync.Mutex
+	states map[string][]byte
+}
+
+// Load returns the onboarding state for the id
+func (m *MemoryOnboardingStateStore) Load(ctx context.Context, id string) (*OnboardingState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.states[id]
+	if !ok {
+		return nil, nil
+	}
+
+	var state OnboardingState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Save stores the onboarding state
+func (m *MemoryOnboardingStateStore) Save(ctx context.Context, state *OnboardingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.states == nil {
+		m.states = map[string][]byte{}
+	}
+
+	m.states[state.ID] = data
+
+	return nil
+}
+
+// FileOnboardingStateStore is an onboarding state store that keeps each
+// onboarding as a json file in a directory
+type FileOnboardingStateStore struct {
+	Dir string
+}
+
+// Load returns the onboarding state for the id, or nil if the file does
+// not exist
+func (f *FileOnboardingStateStore) Load(ctx context.Context, id string) (*OnboardingState, error) {
+	data, err := ioutil.ReadFile(f.path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var state OnboardingState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Save stores the onboarding state
+func (f *FileOnboardingStateStore) Save(ctx context.Context, state *OnboardingState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(f.path(state.ID), data)
+}
+
+// path returns the state file path for the id
+func (f *FileOnboardingStateStore) path(id string) string {
+	return filepath.Join(f.Dir, strings.Replace(id, string(filepath.Separator), "", -1)+".json")
+}
+
+// Onboarding onboards a verified customer
+//
+// Run creates the customer, uploads its documents, adds and verifies its
+// beneficial owners and certifies beneficial ownership, saving progress to
+// the store after every change. When dwolla needs time, such as while a
+// document is reviewed, Run stops and records what it is waiting on; call
+// Run again later to resume. Documents are only uploaded once, so append
+// new documents to resubmit after a rejection.
+//
+// Beneficial owners and certification are skipped for personal customers
+// and sole proprietorships.
+type Onboarding struct {
+	Client            *Client
+	Store             OnboardingStateStore
+	ID                string
+	Customer          CustomerRequestBuilder
+	CustomerDocuments []*DocumentRequest
+	BeneficialOwners  []OnboardingBeneficialOwner
+}
+
+// NewOnboarding initializes a new onboarding
+func NewOnboarding(client *Client, store OnboardingStateStore, id string, customer CustomerRequestBuilder) *Onboarding {
+	return &Onboarding{
+		Client:   client,
+		Store:    store,
+		ID:       id,
+		Customer: customer,
+	}
+}
+
+// Progress returns the stored progress of the onboarding
+func (o *Onboarding) Progress(ctx context.Context) (*OnboardingProgress, error) {
+	state, err := o.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := state.Progress()
+
+	return &progress, nil
+}
+
+// Run runs the onboarding until it completes or has to wait on dwolla and
+// returns the saved state
+func (o *Onboarding) Run(ctx context.Context) (*OnboardingState, error) {
+	if o.Client == nil || o.Store == nil || o.Customer == nil || o.ID == "" {
+		return nil, errors.New("Onboarding requires a client, store, id and customer")
+	}
+
+	state, err := o.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Waiting = ""
+
+	err = o.run(ctx, state)
+
+	state.LastError = ""
+	if err != nil {
+		state.LastError = err.Error()
+	}
+
+	if saveErr := o.save(ctx, state); err == nil {
+		err = saveErr
+	}
+
+	return state, err
+}
+
+// run advances the onboarding as far as it can
+func (o *Onboarding) run(ctx context.Context, state *OnboardingState) error {
+	request, err := o.Customer.Build()
+	if err != nil {
+		return err
+	}
+
+	customer, err := o.customer(ctx, state, request)
+	if err != nil {
+		return err
+	}
+
+	state.CustomerStatus = customer.Status
+	state.Step = OnboardingStepCustomerVerification
+
+	switch customer.Status {
+	case CustomerStatusVerified:
+		state.Step = OnboardingStepBeneficialOwners
+	case CustomerStatusDocument:
+		if err := o.uploadCustomerDocuments(ctx, state, customer); err != nil {
+			return err
+		}
+	case CustomerStatusRetry, CustomerStatusSuspended, CustomerStatusDeactivated:
+		state.Waiting = fmt.Sprintf("Customer status is %s", customer.Status)
+		return nil
+	default:
+		state.Waiting = fmt.Sprintf("Customer status is %s", customer.Status)
+	}
+
+	business := request.Type == CustomerTypeBusiness && request.BusinessType != BusinessTypeSoleProprietorship
+
+	if business {
+		verification := state.Step
+		state.Step = OnboardingStepBeneficialOwners
+
+		if err := o.save(ctx, state); err != nil {
+			return err
+		}
+
+		if err := o.beneficialOwners(ctx, state, customer); err != nil {
+			return err
+		}
+
+		// Owners are added while the customer is still being verified, but
+		// the customer remains the step being waited on.
+		if customer.Status != CustomerStatusVerified {
+			state.Step = verification
+		}
+	}
+
+	if state.Waiting != "" {
+		return nil
+	}
+
+	if business {
+		state.Step = OnboardingStepCertifyOwnership
+
+		if err := o.certify(ctx, state, customer); err != nil {
+			return err
+		}
+	}
+
+	state.Step = OnboardingStepComplete
+
+	return nil
+}
+
+// customer creates the customer, or retrieves it if it already exists
+//
+// If the state has no customer yet but dwolla already has one with the
+// request's email, the onboarding crashed after creating it and the
+// existing customer is used. Only the first customer of the request's type
+// that is not deactivated or suspended is adopted, and when the request has
+// a correlation id the customer's must match it; if the email only matches
+// other customers an error is returned.
+func (o *Onboarding) customer(ctx context.Context, state *OnboardingState, request *CustomerRequest) (*Customer, error) {
+	if state.CustomerURL == "" && request.Email != "" {
+		customers, err := o.Client.Customer.List(ctx, (&CustomerListOptions{Email: request.Email}).Values())
+		if err != nil {
+			return nil, err
+		}
+
+		var rejected *Customer
+
+		for i, customer := range customers.Embedded["customers"] {
+			if !strings.EqualFold(customer.Email, request.Email) {
+				continue
+			}
+
+			if customer.Type != request.Type || customer.Status == CustomerStatusDeactivated || customer.Status == CustomerStatusSuspended ||
+				(request.CorrelationID != "" && customer.CorrelationID != request.CorrelationID) {
+				rejected = &customers.Embedded["customers"][i]
+				continue
+			}
+
+			state.CustomerURL = customer.Links["self"].Href
+
+			break
+		}
+
+		if state.CustomerURL == "" && rejected != nil {
+			return nil, fmt.Errorf("Existing %s customer %s with status %s can not be adopted for a %s customer", rejected.Type, rejected.ID, rejected.Status, request.Type)
+		}
+	}
+
+	if state.CustomerURL == "" {
+		customer, err := o.Client.Customer.Create(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		state.CustomerURL = customer.Links["self"].Href
+
+		if err := o.save(ctx, state); err != nil {
+			return nil, err
+		}
+	}
+
+	var customer Customer
+
+	if err := o.Client.Get(ctx, state.CustomerURL, nil, nil, &customer); err != nil {
+		return nil, err
+	}
+
+	customer.client = o.Client
+
+	return &customer, nil
+}
+
+// uploadCustomerDocuments uploads the customer documents that have not
+// been uploaded yet
+func (o *Onboarding) uploadCustomerDocuments(ctx context.Context, state *OnboardingState, customer *Customer) error {
+	for i := len(state.CustomerDocuments); i < len(o.CustomerDocuments); i++ {
+		document, err := customer.CreateDocument(ctx, o.CustomerDocuments[i])
+		if err != nil {
+			return err
+		}
+
+		state.CustomerDocuments = append(state.CustomerDocuments, document.Links["self"].Href)
+
+		if err := o.save(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	state.Waiting = "Waiting for customer documents to be reviewed"
+
+	if len(o.CustomerDocuments) == 0 {
+		state.Waiting = "Customer documents required"
+	}
+
+	return nil
+}
+
+// beneficialOwners adds each beneficial owner that has not been added and
+// uploads documents for the owners that need them
+func (o *Onboarding) beneficialOwners(ctx context.Context, state *OnboardingState, customer *Customer) error {
+	for len(state.BeneficialOwners) < len(o.BeneficialOwners) {
+		state.BeneficialOwners = append(state.BeneficialOwners, OnboardingBeneficialOwnerState{})
+	}
+
+	var existing []BeneficialOwner
+
+	for i, desired := range o.BeneficialOwners {
+		owner := &state.BeneficialOwners[i]
+
+		if owner.URL == "" && existing == nil {
+			owners, err := customer.ListBeneficialOwners(ctx)
+			if err != nil {
+				return err
+			}
+
+			existing = append([]BeneficialOwner{}, owners.Embedded["beneficial-owners"]...)
+		}
+
+		if owner.URL == "" {
+			url, err := o.adoptBeneficialOwner(state, existing, desired.Request)
+			if err != nil {
+				return err
+			}
+
+			owner.URL = url
+		}
+
+		if owner.URL == "" {
+			created, err := customer.CreateBeneficialOwner(ctx, desired.Request)
+			if err != nil {
+				return err
+			}
+
+			owner.URL = created.Links["self"].Href
+
+			if err := o.save(ctx, state); err != nil {
+				return err
+			}
+		}
+
+		var retrieved BeneficialOwner
+
+		if err := o.Client.Get(ctx, owner.URL, nil, nil, &retrieved); err != nil {
+			return err
+		}
+
+		retrieved.client = o.Client
+		owner.Status = retrieved.VerificationStatus
+
+		if owner.Status != BeneficialOwnerStatusDocument {
+			continue
+		}
+
+		for j := len(owner.Documents); j < len(desired.Documents); j++ {
+			document, err := retrieved.CreateDocument(ctx, desired.Documents[j])
+			if err != nil {
+				return err
+			}
+
+			owner.Documents = append(owner.Documents, document.Links["self"].Href)
+
+			if err := o.save(ctx, state); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, owner := range state.BeneficialOwners {
+		if owner.Status != BeneficialOwnerStatusVerified && state.Waiting == "" {
+			state.Waiting = fmt.Sprintf("Beneficial owner status is %s", owner.Status)
+		}
+	}
+
+	return nil
+}
+
+// adoptBeneficialOwner returns the url of an existing beneficial owner with
+// the same name that the state does not already track
+//
+// This recovers owners created by an onboarding that crashed before saving
+// them. Owners are only matched on name, so an error is returned rather
+// than guessing when more than one untracked owner, or more than one owner
+// still to be added, has the name.
+func (o *Onboarding) adoptBeneficialOwner(state *OnboardingState, existing []BeneficialOwner, request *BeneficialOwnerRequest) (string, error) {
+	sameName := func(firstName, lastName string) bool {
+		return strings.EqualFold(firstName, request.FirstName) && strings.EqualFold(lastName, request.LastName)
+	}
+
+	var matches []string
+
+	for _, owner := range existing {
+		if !sameName(owner.FirstName, owner.LastName) {
+			continue
+		}
+
+		tracked := false
+
+		for _, s := range state.BeneficialOwners {
+			if s.URL == owner.Links["self"].Href {
+				tracked = true
+			}
+		}
+
+		if !tracked {
+			matches = append(matches, owner.Links["self"].Href)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", nil
+	}
+
+	pending := 0
+
+	for i, desired := range o.BeneficialOwners {
+		if state.BeneficialOwners[i].URL == "" && sameName(desired.Request.FirstName, desired.Request.LastName) {
+			pending++
+		}
+	}
+
+	if len(matches) > 1 || pending > 1 {
+		return "", fmt.Errorf("Beneficial owner %s %s is ambiguous: %d existing and %d new beneficial owners have the name", request.FirstName, request.LastName, len(matches), pending)
+	}
+
+	return matches[0], nil
+}
+
+// certify certifies beneficial ownership unless it is already certified
+func (o *Onboarding) certify(ctx context.Context, state *OnboardingState, customer *Customer) error {
+	if state.OwnershipStatus == CertificationStatusCertified {
+		return nil
+	}
+
+	ownership, err := customer.RetrieveBeneficialOwnership(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ownership.Status != CertificationStatusCertified {
+		if err := customer.CertifyBeneficialOwnership(ctx); err != nil {
+			return err
+		}
+	}
+
+	state.OwnershipStatus = CertificationStatusCertified
+
+	return o.save(ctx, state)
+}
+
+// load returns the stored state, or a new state if there is none
+func (o *Onboarding) load(ctx context.Context) (*OnboardingState, error) {
+	state, err := o.Store.Load(ctx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		state = &OnboardingState{ID: o.ID, Step: OnboardingStepCreateCustomer}
+	}
+
+	return state, nil
+}
+
+// save stores the state
+func (o *Onboarding) save(ctx context.Context, state *OnboardingState) error {
+	state.Updated = time.Now().UTC()
+
+	return o.Store.Save(ctx, state)
+}
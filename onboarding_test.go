@@ -0,0 +1,260 @@
+package dwolla
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stepRecordingStore records the step of every saved onboarding state
+type stepRecordingStore struct {
+	OnboardingStateStore
+	steps []OnboardingStep
+}
+
+func (s *stepRecordingStore) Save(ctx context.Context, state *OnboardingState) error {
+	s.steps = append(s.steps, state.Step)
+
+	return s.OnboardingStateStore.Save(ctx, state)
+}
+
+func TestOnboardingRun(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers":                                              {200, filepath.Join("testdata", "customers-empty.json")},
+		"POST /customers":                                             {201, filepath.Join("testdata", "customer-business.json")},
+		"GET " + testCustomerPath:                                     {200, filepath.Join("testdata", "customer-business.json")},
+		"GET " + testCustomerPath + "/beneficial-owners":              {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"POST " + testCustomerPath + "/beneficial-owners":             {201, filepath.Join("testdata", "beneficial-owner.json")},
+		"GET /beneficial-owners/07d59716-ef22-4fe6-98e8-f3190233dfb8": {200, filepath.Join("testdata", "beneficial-owner.json")},
+		"GET /beneficial-owners/caa81a5f-ec1e-4559-8b32-d90655bfd03c": {200, filepath.Join("testdata", "beneficial-owner.json")},
+		"GET " + testCustomerPath + "/beneficial-ownership":           {200, filepath.Join("testdata", "beneficial-ownership.json")},
+		"POST " + testCustomerPath + "/beneficial-ownership":          {200, filepath.Join("testdata", "beneficial-ownership.json")},
+	})
+
+	store := &MemoryOnboardingStateStore{}
+
+	onboarding := NewOnboarding(c, store, "jane-corp", newTestBusinessVerifiedCustomerRequest())
+	onboarding.BeneficialOwners = []OnboardingBeneficialOwner{
+		{Request: &BeneficialOwnerRequest{FirstName: "beneficial", LastName: "owner"}},
+		{Request: &BeneficialOwnerRequest{FirstName: "Joe", LastName: "Owner2"}},
+	}
+
+	state, err := onboarding.Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepComplete)
+	assert.Equal(t, state.CustomerURL, "https://api-sandbox.dwolla.com"+testCustomerPath)
+	assert.Equal(t, state.BeneficialOwners[0].URL, "https://api-sandbox.dwolla.com/beneficial-owners/07d59716-ef22-4fe6-98e8-f3190233dfb8")
+	assert.Equal(t, state.BeneficialOwners[1].URL, "https://api-sandbox.dwolla.com/beneficial-owners/caa81a5f-ec1e-4559-8b32-d90655bfd03c")
+	assert.Equal(t, state.OwnershipStatus, CertificationStatusCertified)
+	assert.Empty(t, state.Waiting)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-owners"), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-ownership"), 1)
+
+	state, err = onboarding.Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepComplete)
+	assert.Equal(t, countMockRequests(mc, "POST", "/customers"), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-owners"), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-ownership"), 1)
+
+	progress, err := onboarding.Progress(ctx)
+
+	assert.Nil(t, err)
+	assert.True(t, progress.Complete)
+	assert.True(t, progress.Certified)
+	assert.Equal(t, progress.CompletedSteps, progress.TotalSteps)
+	assert.Equal(t, progress.BeneficialOwners, 2)
+	assert.Equal(t, progress.VerifiedBeneficialOwners, 2)
+}
+
+func TestOnboardingRunDocument(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers":                                                         {200, filepath.Join("testdata", "customers-empty.json")},
+		"POST /customers":                                                        {201, filepath.Join("testdata", "customer-business-document.json")},
+		"GET " + testCustomerPath:                                                {200, filepath.Join("testdata", "customer-business-document.json")},
+		"POST " + testCustomerPath + "/documents":                                {201, filepath.Join("testdata", "document.json")},
+		"GET " + testCustomerPath + "/beneficial-owners":                         {200, filepath.Join("testdata", "beneficial-owners.json")},
+		"GET /beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98":            {200, filepath.Join("testdata", "beneficial-owner-document.json")},
+		"POST /beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98/documents": {201, filepath.Join("testdata", "document.json")},
+	})
+
+	dir, _ := ioutil.TempDir("", "dwolla")
+	defer os.RemoveAll(dir)
+
+	store := &stepRecordingStore{OnboardingStateStore: &FileOnboardingStateStore{Dir: dir}}
+
+	onboarding := NewOnboarding(c, store, "jane-corp", newTestBusinessVerifiedCustomerRequest())
+	onboarding.CustomerDocuments = []*DocumentRequest{newTestDocumentRequest()}
+	onboarding.BeneficialOwners = []OnboardingBeneficialOwner{
+		{Request: &BeneficialOwnerRequest{FirstName: "document", LastName: "owner1"}, Documents: []*DocumentRequest{newTestDocumentRequest()}},
+	}
+
+	state, err := onboarding.Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepCustomerVerification)
+	assert.Contains(t, store.steps, OnboardingStepBeneficialOwners)
+	assert.Equal(t, state.CustomerStatus, CustomerStatusDocument)
+	assert.Equal(t, state.Waiting, "Waiting for customer documents to be reviewed")
+	assert.Len(t, state.CustomerDocuments, 1)
+	assert.Len(t, state.BeneficialOwners[0].Documents, 1)
+	assert.Equal(t, state.BeneficialOwners[0].Status, BeneficialOwnerStatusDocument)
+
+	// A fresh onboarding resumes from the stored state without uploading
+	// the documents again.
+	onboarding = NewOnboarding(c, store, "jane-corp", newTestBusinessVerifiedCustomerRequest())
+	onboarding.CustomerDocuments = []*DocumentRequest{newTestDocumentRequest()}
+	onboarding.BeneficialOwners = []OnboardingBeneficialOwner{
+		{Request: &BeneficialOwnerRequest{FirstName: "document", LastName: "owner1"}, Documents: []*DocumentRequest{newTestDocumentRequest()}},
+	}
+
+	state, err = onboarding.Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepCustomerVerification)
+	assert.Equal(t, countMockRequests(mc, "POST", "/customers"), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/documents"), 1)
+	assert.Equal(t, countMockRequests(mc, "POST", "/beneficial-owners/55469604-40ab-44b6-962f-de2c0837ba98/documents"), 1)
+
+	progress, err := onboarding.Progress(ctx)
+
+	assert.Nil(t, err)
+	assert.False(t, progress.Complete)
+	assert.Equal(t, progress.CompletedSteps, 1)
+	assert.Equal(t, progress.DocumentsUploaded, 2)
+	assert.Equal(t, progress.VerifiedBeneficialOwners, 0)
+}
+
+func TestOnboardingRunExistingCustomer(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers": {200, filepath.Join("testdata", "customers-personal.json")},
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F": {200, filepath.Join("testdata", "customer-verified.json")},
+	})
+
+	onboarding := NewOnboarding(c, &MemoryOnboardingStateStore{}, "jane", newTestPersonalVerifiedCustomerRequest())
+	state, err := onboarding.Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepComplete)
+	assert.Equal(t, state.CustomerURL, "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F")
+	assert.Equal(t, countMockRequests(mc, "POST", "/customers"), 0)
+}
+
+func TestOnboardingRunExistingCustomerCorrelationID(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers": {200, filepath.Join("testdata", "customers-personal.json")},
+		"GET /customers/FC451A7A-AE30-4404-AB95-E3553FCD733F": {200, filepath.Join("testdata", "customer-verified.json")},
+	})
+
+	request := newTestPersonalVerifiedCustomerRequest()
+	request.CorrelationID = "jane-1"
+
+	state, err := NewOnboarding(c, &MemoryOnboardingStateStore{}, "jane", request).Run(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.CustomerURL, "https://api-sandbox.dwolla.com/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F")
+
+	request.CorrelationID = "jane-2"
+
+	state, err = NewOnboarding(c, &MemoryOnboardingStateStore{}, "jane", request).Run(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, state.CustomerURL, "")
+	assert.Equal(t, countMockRequests(mc, "POST", "/customers"), 0)
+}
+
+func TestOnboardingRunAmbiguousBeneficialOwner(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET " + testCustomerPath:                        {200, filepath.Join("testdata", "customer-business.json")},
+		"GET " + testCustomerPath + "/beneficial-owners": {200, filepath.Join("testdata", "beneficial-owners.json")},
+	})
+
+	store := &MemoryOnboardingStateStore{}
+	err := store.Save(ctx, &OnboardingState{ID: "jane-corp", Step: OnboardingStepCustomerVerification, CustomerURL: "https://api-sandbox.dwolla.com" + testCustomerPath})
+
+	assert.Nil(t, err)
+
+	onboarding := NewOnboarding(c, store, "jane-corp", newTestBusinessVerifiedCustomerRequest())
+	onboarding.BeneficialOwners = []OnboardingBeneficialOwner{
+		{Request: &BeneficialOwnerRequest{FirstName: "Joe", LastName: "Owner2"}},
+		{Request: &BeneficialOwnerRequest{FirstName: "Joe", LastName: "Owner2"}},
+	}
+
+	state, err := onboarding.Run(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, state.Step, OnboardingStepBeneficialOwners)
+	assert.Equal(t, state.BeneficialOwners[0].URL, "")
+	assert.Equal(t, countMockRequests(mc, "POST", testCustomerPath+"/beneficial-owners"), 0)
+}
+
+func TestOnboardingRunExistingCustomerRejected(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers": {200, filepath.Join("testdata", "customers.json")},
+	})
+
+	onboarding := NewOnboarding(c, &MemoryOnboardingStateStore{}, "jane", newTestPersonalVerifiedCustomerRequest())
+	state, err := onboarding.Run(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, state.Step, OnboardingStepCreateCustomer)
+	assert.Equal(t, state.CustomerURL, "")
+	assert.Equal(t, countMockRequests(mc, "POST", "/customers"), 0)
+	assert.Equal(t, countMockRequests(mc, "GET", "/customers/FC451A7A-AE30-4404-AB95-E3553FCD733F"), 0)
+}
+
+func TestOnboardingRunError(t *testing.T) {
+	onboarding := &Onboarding{}
+	state, err := onboarding.Run(ctx)
+
+	assert.Error(t, err)
+	assert.Nil(t, state)
+
+	c, _ := newMockRoutedClient(map[string]mockRoute{
+		"GET /customers":  {200, filepath.Join("testdata", "customers-empty.json")},
+		"POST /customers": {400, filepath.Join("testdata", "validation-error.json")},
+	})
+
+	store := &MemoryOnboardingStateStore{}
+
+	onboarding = NewOnboarding(c, store, "jane", newTestPersonalVerifiedCustomerRequest())
+	state, err = onboarding.Run(ctx)
+
+	assert.Error(t, err)
+	assert.Equal(t, state.Step, OnboardingStepCreateCustomer)
+	assert.Equal(t, state.LastError, err.Error())
+
+	progress, err := onboarding.Progress(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, progress.LastError, state.LastError)
+	assert.Equal(t, progress.CompletedSteps, 0)
+}
+
+func TestFileOnboardingStateStore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "dwolla")
+	defer os.RemoveAll(dir)
+
+	store := &FileOnboardingStateStore{Dir: dir}
+
+	state, err := store.Load(ctx, "missing")
+
+	assert.Nil(t, err)
+	assert.Nil(t, state)
+
+	err = store.Save(ctx, &OnboardingState{ID: "jane", Step: OnboardingStepBeneficialOwners, CustomerURL: "https://api-sandbox.dwolla.com" + testCustomerPath})
+
+	assert.Nil(t, err)
+
+	state, err = store.Load(ctx, "jane")
+
+	assert.Nil(t, err)
+	assert.Equal(t, state.Step, OnboardingStepBeneficialOwners)
+	assert.Equal(t, state.CustomerURL, "https://api-sandbox.dwolla.com"+testCustomerPath)
+}
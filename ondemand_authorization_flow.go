@@ -0,0 +1,130 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrOnDemandAuthorizationRequired is returned when a customer funding
+// source, including one created from an exchange, has no on-demand
+// authorization and the client requires one
+var ErrOnDemandAuthorizationRequired = errors.New("on-demand authorization is required")
+
+// OnDemandAuthorizationAcceptance is a record of a customer accepting an
+// on-demand transfer authorization
+//
+// It holds the text that was shown, so it can be stored as evidence of the
+// customer's consent.
+type OnDemandAuthorizationAcceptance struct {
+	Authorization string    `json:"authorization"`
+	BodyText      string    `json:"bodyText"`
+	ButtonText    string    `json:"buttonText"`
+	AcceptedAt    time.Time `json:"acceptedAt"`
+	IPAddress     string    `json:"ipAddress"`
+}
+
+// Validate checks that the acceptance references an authorization and
+// records when and from where it was accepted
+func (a *OnDemandAuthorizationAcceptance) Validate() error {
+	var errs validationErrors
+
+	if a.Authorization == "" {
+		errs.add("Required", "/authorization", "Authorization required.")
+	}
+
+	if strings.TrimSpace(a.BodyText) == "" {
+		errs.add("Required", "/bodyText", "BodyText required.")
+	}
+
+	if a.AcceptedAt.IsZero() {
+		errs.add("Required", "/acceptedAt", "AcceptedAt required.")
+	}
+
+	if a.IPAddress == "" {
+		errs.add("Required", "/ipAddress", "IpAddress required.")
+	} else if net.ParseIP(a.IPAddress) == nil {
+		errs.add("InvalidFormat", "/ipAddress", "IpAddress must be an IPv4 or IPv6 address.")
+	}
+
+	return errs.err()
+}
+
+// Accept records the customer's acceptance of the authorization from the
+// ip address at the current time
+//
+// The body and button text should be shown to the customer before they
+// accept. Store the returned record and pass it to
+// Customer.CreateAuthorizedFundingSource.
+func (o *OnDemandAuthorization) Accept(ipAddress string) (*OnDemandAuthorizationAcceptance, error) {
+	if _, ok := o.Links["self"]; !ok {
+		return nil, errors.New("No self resource link")
+	}
+
+	acceptance := &OnDemandAuthorizationAcceptance{
+		Authorization: o.Links["self"].Href,
+		BodyText:      o.BodyText,
+		ButtonText:    o.ButtonText,
+		AcceptedAt:    time.Now().UTC(),
+		IPAddress:     ipAddress,
+	}
+
+	if err := acceptance.Validate(); err != nil {
+		return nil, err
+	}
+
+	return acceptance, nil
+}
+
+// SetOnDemandAuthorization attaches the on-demand authorization link to the
+// funding source request
+func (f *FundingSourceRequest) SetOnDemandAuthorization(href string) {
+	if f.Links == nil {
+		f.Links = Links{}
+	}
+
+	f.Links["on-demand-authorization"] = Link{Href: href}
+}
+
+// OnDemandAuthorization returns the attached on-demand authorization link
+func (f *FundingSourceRequest) OnDemandAuthorization() (string, bool) {
+	link, ok := f.Links["on-demand-authorization"]
+
+	return link.Href, ok && link.Href != ""
+}
+
+// CreateAuthorizedFundingSource creates a funding source for the customer
+// with the accepted on-demand authorization attached
+//
+// The acceptance is validated before the request is sent.
+//
+// see: https://docsv2.dwolla.com/#create-a-funding-source-for-a-customer
+func (c *Customer) CreateAuthorizedFundingSource(ctx context.Context, body *FundingSourceRequest, acceptance *OnDemandAuthorizationAcceptance) (*FundingSource, error) {
+	if acceptance == nil {
+		return nil, ErrOnDemandAuthorizationRequired
+	}
+
+	if err := acceptance.Validate(); err != nil {
+		return nil, err
+	}
+
+	body.SetOnDemandAuthorization(acceptance.Authorization)
+
+	return c.CreateFundingSource(ctx, body)
+}
+
+// checkOnDemandAuthorization returns ErrOnDemandAuthorizationRequired if
+// the client requires on-demand authorizations and the request has none
+func (c *Client) checkOnDemandAuthorization(body *FundingSourceRequest) error {
+	if !c.RequireOnDemandAuthorization {
+		return nil
+	}
+
+	if _, ok := body.OnDemandAuthorization(); !ok {
+		return ErrOnDemandAuthorizationRequired
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package dwolla
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnDemandAuthorizationAccept(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "on-demand-authorization.json"))
+	authorization, err := c.OnDemandAuthorization.Create(ctx)
+
+	assert.Nil(t, err)
+
+	before := time.Now()
+	acceptance, err := authorization.Accept("203.0.113.7")
+
+	assert.Nil(t, err)
+	assert.Equal(t, testOnDemandAuthorization, acceptance.Authorization)
+	assert.Equal(t, authorization.BodyText, acceptance.BodyText)
+	assert.Equal(t, "Agree & Continue", acceptance.ButtonText)
+	assert.Equal(t, "203.0.113.7", acceptance.IPAddress)
+	assert.False(t, acceptance.AcceptedAt.Before(before.Add(-time.Second)))
+	assert.Equal(t, time.UTC, acceptance.AcceptedAt.Location())
+
+	data, err := json.Marshal(acceptance)
+
+	assert.Nil(t, err)
+
+	var record OnDemandAuthorizationAcceptance
+
+	assert.Nil(t, json.Unmarshal(data, &record))
+	assert.True(t, record.AcceptedAt.Equal(acceptance.AcceptedAt))
+	assert.Equal(t, acceptance.IPAddress, record.IPAddress)
+
+	acceptance, err = authorization.Accept("2001:db8::1")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, acceptance)
+}
+
+func TestOnDemandAuthorizationAcceptError(t *testing.T) {
+	authorization := &OnDemandAuthorization{BodyText: "I agree", ButtonText: "Agree"}
+	res, err := authorization.Accept("203.0.113.7")
+
+	assert.Error(t, err)
+	assert.Equal(t, "No self resource link", err.Error())
+	assert.Nil(t, res)
+
+	authorization.Links = Links{"self": Link{Href: testOnDemandAuthorization}}
+	res, err = authorization.Accept("")
+
+	assert.Equal(t, []string{"/ipAddress"}, validationErrorPaths(err))
+	assert.Nil(t, res)
+
+	res, err = authorization.Accept("not-an-ip")
+
+	assert.Equal(t, []string{"/ipAddress"}, validationErrorPaths(err))
+	assert.Nil(t, res)
+}
+
+func TestOnDemandAuthorizationAcceptanceValidate(t *testing.T) {
+	acceptance := &OnDemandAuthorizationAcceptance{}
+
+	assert.Equal(t, []string{"/authorization", "/bodyText", "/acceptedAt", "/ipAddress"}, validationErrorPaths(acceptance.Validate()))
+}
+
+func TestFundingSourceRequestSetOnDemandAuthorization(t *testing.T) {
+	body := newTestFundingSourceRequest()
+
+	_, ok := body.OnDemandAuthorization()
+	assert.False(t, ok)
+
+	body.SetOnDemandAuthorization(testOnDemandAuthorization)
+
+	href, ok := body.OnDemandAuthorization()
+	assert.True(t, ok)
+	assert.Equal(t, testOnDemandAuthorization, href)
+
+	data, err := json.Marshal(body)
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"on-demand-authorization":{"href":"`+testOnDemandAuthorization+`"}`)
+}
+
+func TestCustomerCreateAuthorizedFundingSource(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST /on-demand-authorizations":                {200, filepath.Join("testdata", "on-demand-authorization.json")},
+		"POST " + testCustomerPath + "/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	c.RequireOnDemandAuthorization = true
+	customer := newTestCustomer(c)
+
+	authorization, err := c.OnDemandAuthorization.Create(ctx)
+	assert.Nil(t, err)
+
+	acceptance, err := authorization.Accept("203.0.113.7")
+	assert.Nil(t, err)
+
+	source, err := customer.CreateAuthorizedFundingSource(ctx, newTestFundingSourceRequest(), acceptance)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, source)
+	assert.Len(t, mc.requestLog(), 2)
+
+	reader, err := mc.requestLog()[1].GetBody()
+	assert.Nil(t, err)
+
+	data, _ := ioutil.ReadAll(reader)
+
+	assert.Contains(t, string(data), `"on-demand-authorization":{"href":"`+testOnDemandAuthorization+`"}`)
+}
+
+func TestCustomerCreateAuthorizedFundingSourceError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testCustomerPath + "/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	customer := newTestCustomer(c)
+
+	res, err := customer.CreateAuthorizedFundingSource(ctx, newTestFundingSourceRequest(), nil)
+
+	assert.Equal(t, ErrOnDemandAuthorizationRequired, err)
+	assert.Nil(t, res)
+
+	res, err = customer.CreateAuthorizedFundingSource(ctx, newTestFundingSourceRequest(), &OnDemandAuthorizationAcceptance{Authorization: testOnDemandAuthorization})
+
+	assert.Equal(t, []string{"/bodyText", "/acceptedAt", "/ipAddress"}, validationErrorPaths(err))
+	assert.Nil(t, res)
+	assert.Len(t, mc.requestLog(), 0)
+}
+
+func TestCustomerCreateFundingSourceRequireOnDemandAuthorization(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"POST " + testCustomerPath + "/funding-sources": {201, filepath.Join("testdata", "funding-source.json")},
+	})
+
+	customer := newTestCustomer(c)
+
+	_, err := customer.CreateFundingSource(ctx, newTestFundingSourceRequest())
+
+	assert.Nil(t, err)
+	assert.Len(t, mc.requestLog(), 1)
+
+	c.RequireOnDemandAuthorization = true
+	c.DisableValidation = true
+
+	res, err := customer.CreateFundingSource(ctx, newTestFundingSourceRequest())
+
+	assert.Equal(t, ErrOnDemandAuthorizationRequired, err)
+	assert.Nil(t, res)
+	assert.Len(t, mc.requestLog(), 1)
+
+	body := newTestFundingSourceRequest()
+	body.SetOnDemandAuthorization(testOnDemandAuthorization)
+
+	res, err = customer.CreateFundingSource(ctx, body)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Len(t, mc.requestLog(), 2)
+}
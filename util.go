@@ -1,11 +1,15 @@
 package dwolla
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -99,6 +103,28 @@ func IDFromHREF(href string) (string, error) {
 	return href[lastIDX:], nil
 }
 
+// writeFileAtomic writes data to a temporary file in the same directory
+// and renames it over path, so a crash never leaves a partial file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
 type mockHTTPClient struct {
 	err error
 	res *http.Response
@@ -131,3 +157,220 @@ func newMockClient(status int, file string) *Client {
 	c.Token = &Token{ExpiresIn: 3600, startTime: time.Now()}
 	return c
 }
+
+// mockRoute is a canned response for a mocked request
+type mockRoute struct {
+	status int
+	file   string
+}
+
+// mockRoutedHTTPClient responds to requests based on method and path
+type mockRoutedHTTPClient struct {
+	mu       sync.Mutex
+	routes   map[string]mockRoute
+	requests []*http.Request
+}
+
+func (m *mockRoutedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+
+	key := fmt.Sprintf("%s %s", req.Method, req.URL.Path)
+
+	route, ok := m.routes[fmt.Sprintf("%s?%s", key, req.URL.RawQuery)]
+	if !ok {
+		route, ok = m.routes[key]
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no mock route for %s", key)
+	}
+
+	f, err := os.Open(route.file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{Body: f, StatusCode: route.status, Header: http.Header{}}, nil
+}
+
+// requestLog returns the requests received so far
+func (m *mockRoutedHTTPClient) requestLog() []*http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*http.Request{}, m.requests...)
+}
+
+const (
+	// testCustomerPath is the path of the business customer in
+	// testdata/customer-business.json
+	testCustomerPath = "/customers/56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc"
+	// testCustomerA, testCustomerB and testCustomerC are the customer ids in
+	// testdata/customers-page-1.json and testdata/customers-page-2.json
+	testCustomerA = "0a1b6a54-5f38-4b6c-9a2c-3c7d2c1f0a01"
+	testCustomerB = "0a1b6a54-5f38-4b6c-9a2c-3c7d2c1f0b02"
+	testCustomerC = "0a1b6a54-5f38-4b6c-9a2c-3c7d2c1f0c03"
+	// testOnDemandAuthorization is the self link in
+	// testdata/on-demand-authorization.json
+	testOnDemandAuthorization = "https://api-sandbox.dwolla.com/on-demand-authorizations/30e7c028-0bdf-e511-80de-0aa34a9b2388"
+)
+
+func countMockRequests(mc *mockRoutedHTTPClient, method, path string) int {
+	count := 0
+
+	for _, req := range mc.requestLog() {
+		if req.Method == method && req.URL.Path == path {
+			count++
+		}
+	}
+
+	return count
+}
+
+func newMockRoutedClient(routes map[string]mockRoute) (*Client, *mockRoutedHTTPClient) {
+	mc := &mockRoutedHTTPClient{routes: routes}
+
+	c := NewWithHTTPClient("foobar", "barbaz", Sandbox, mc)
+	c.Token = &Token{ExpiresIn: 3600, startTime: time.Now()}
+	return c, mc
+}
+
+func newTestPersonalVerifiedCustomerRequest() *PersonalVerifiedCustomerRequest {
+	return &PersonalVerifiedCustomerRequest{
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       "janedoe@nomail.com",
+		DateOfBirth: "1970-01-01",
+		SSN:         "1234",
+		Address1:    "99-99 33rd St",
+		City:        "Some City",
+		State:       "NY",
+		PostalCode:  "11101",
+	}
+}
+
+func newTestBusinessVerifiedCustomerRequest() *BusinessVerifiedCustomerRequest {
+	return &BusinessVerifiedCustomerRequest{
+		FirstName:              "Account",
+		LastName:               "Admin",
+		Email:                  "accountadmin@nomail.com",
+		Address1:               "99-99 33rd St",
+		City:                   "Some City",
+		State:                  "NY",
+		PostalCode:             "11101",
+		BusinessName:           "Jane Corp llc",
+		BusinessType:           BusinessTypeLLC,
+		BusinessClassification: "9ed3f670-7d6f-11e3-b1ce-5404a6144203",
+		EIN:                    "00-0000000",
+		Controller: ControllerRequest{
+			FirstName:   "John",
+			LastName:    "Controller",
+			Title:       "CEO",
+			SSN:         "6789",
+			DateOfBirth: "1980-01-31",
+			Address: Address{
+				Address1:            "1749 18th st",
+				City:                "Des Moines",
+				StateProvinceRegion: "IA",
+				PostalCode:          "50266",
+				Country:             "US",
+			},
+		},
+	}
+}
+
+func newTestCustomerRetryRequest() *CustomerRetryRequest {
+	return &CustomerRetryRequest{
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       "janedoe@nomail.com",
+		Type:        CustomerTypePersonal,
+		DateOfBirth: "1970-01-01",
+		SSN:         "123-45-6789",
+		Address1:    "99-99 33rd St",
+		City:        "Some City",
+		State:       "NY",
+		PostalCode:  "11101",
+	}
+}
+
+func validationErrorPaths(err error) []string {
+	paths := []string{}
+
+	for _, e := range err.(ValidationError).Embedded["errors"] {
+		paths = append(paths, e.Path)
+	}
+
+	return paths
+}
+
+func newTestFundingSourceRequest() *FundingSourceRequest {
+	return &FundingSourceRequest{
+		RoutingNumber:   "222222226",
+		AccountNumber:   "0123456789",
+		BankAccountType: FundingSourceBankAccountTypeChecking,
+		Name:            "Checking",
+	}
+}
+
+func newTestDocumentRequest() *DocumentRequest {
+	return &DocumentRequest{Type: DocumentTypePassport, FileName: "passport.png", File: strings.NewReader("passport")}
+}
+
+// newTestCustomer returns the business customer in
+// testdata/customer-business.json using the client
+func newTestCustomer(c *Client) *Customer {
+	customer, _ := newMockClient(200, filepath.Join("testdata", "customer-business.json")).Customer.Retrieve(context.Background(), "56502f7a-fa59-4a2f-8579-0f8bc9d7b9cc")
+	customer.client = c
+
+	return customer
+}
+
+// newTestFundingSource returns the funding source in the testdata file using
+// the client
+func newTestFundingSource(c *Client, file string) *FundingSource {
+	source, _ := newMockClient(200, filepath.Join("testdata", file)).FundingSource.Retrieve(context.Background(), "49dbaa24-1580-4b1c-8b58-24e26656fa31")
+	source.client = c
+
+	return source
+}
+
+func newTestBeneficialOwnerRequest(firstName, lastName, postalCode string) BeneficialOwnerRequest {
+	return BeneficialOwnerRequest{
+		FirstName:   firstName,
+		LastName:    lastName,
+		DateOfBirth: "1970-01-01",
+		SSN:         "123-45-6789",
+		Address: Address{
+			Address1:            "18749 18th st",
+			Address2:            "apt 12",
+			City:                "Des Moines",
+			StateProvinceRegion: "IA",
+			Country:             "US",
+			PostalCode:          postalCode,
+		},
+	}
+}
+
+// newTestRequest returns a request with a copy of the header for testing
+// handlers
+func newTestRequest(method, target string, header http.Header, body string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	return req
+}
+
+// testWebhookBody is a transfer_created webhook
+const testWebhookBody = `{"id":"03c7e14c-7f15-44a2-bcf7-83f2f7e95d50","resourceId":"81BA6F36-CD7C-E511-80DB-0AA34A9B2388","topic":"transfer_created","timestamp":"2015-10-27T17:07:34.207Z","_links":{"resource":{"href":"https://api-sandbox.dwolla.com/transfers/81BA6F36-CD7C-E511-80DB-0AA34A9B2388"}}}`
+
+func newTestWebhookRequest(secret, body string) *http.Request {
+	return newTestRequest(http.MethodPost, "/webhooks", http.Header{WebhookSignatureHeader: {SignWebhook(secret, []byte(body))}}, body)
+}
@@ -0,0 +1,278 @@
+package dwolla
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MinimumAge is the minimum age, in years, of a verified customer,
+// controller or beneficial owner
+const MinimumAge = 18
+
+var (
+	poBoxPattern      = regexp.MustCompile(`(?i)\b(p\.?\s*o\.?\s*box|post\s+office\s+box)\b`)
+	postalCodePattern = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// usStates are the two-letter codes dwolla accepts for U.S. states,
+// districts and territories
+var usStates = map[string]bool{
+	"AK": true, "AL": true, "AR": true, "AS": true, "AZ": true, "CA": true,
+	"CO": true, "CT": true, "DC": true, "DE": true, "FL": true, "GA": true,
+	"GU": true, "HI": true, "IA": true, "ID": true, "IL": true, "IN": true,
+	"KS": true, "KY": true, "LA": true, "MA": true, "MD": true, "ME": true,
+	"MI": true, "MN": true, "MO": true, "MP": true, "MS": true, "MT": true,
+	"NC": true, "ND": true, "NE": true, "NH": true, "NJ": true, "NM": true,
+	"NV": true, "NY": true, "OH": true, "OK": true, "OR": true, "PA": true,
+	"PR": true, "RI": true, "SC": true, "SD": true, "TN": true, "TX": true,
+	"UM": true, "UT": true, "VA": true, "VI": true, "VT": true, "WA": true,
+	"WI": true, "WV": true, "WY": true,
+}
+
+// validator is a request that can be validated before it is sent
+type validator interface {
+	Validate() error
+}
+
+// validatorFunc is a function that implements validator
+type validatorFunc func() error
+
+// Validate calls the function
+func (f validatorFunc) Validate() error {
+	return f()
+}
+
+// validate runs the request's client side validation unless the client has
+// validation disabled
+func (c *Client) validate(v validator) error {
+	if c.DisableValidation {
+		return nil
+	}
+
+	return v.Validate()
+}
+
+// Validate checks the supplied customer fields against dwolla's documented
+// rules
+//
+// Only fields that are set are checked, so partial update requests can be
+// validated too. Missing required fields are left to the type specific
+// requests, such as PersonalVerifiedCustomerRequest.
+func (c *CustomerRequest) Validate() error {
+	var errs validationErrors
+
+	switch c.Type {
+	case CustomerTypeUnverified, CustomerTypeReceiveOnly:
+		if c.SSN != "" {
+			errs.add("Invalid", "/ssn", "ssn is not allowed for unverified and receive-only customers.")
+		}
+
+		if c.DateOfBirth != "" {
+			errs.add("Invalid", "/dateOfBirth", "dateOfBirth is not allowed for unverified and receive-only customers.")
+		}
+	case CustomerTypePersonal:
+		if c.BusinessType != "" {
+			errs.add("Invalid", "/businessType", "businessType is not allowed for personal customers.")
+		}
+	}
+
+	if c.Type != "" && c.Type != CustomerTypeBusiness && c.Controller != nil {
+		errs.add("Invalid", "/controller", "controller is only allowed for business customers.")
+	}
+
+	if c.DateOfBirth != "" {
+		validateDateOfBirth(&errs, "/dateOfBirth", c.DateOfBirth)
+	}
+
+	if c.SSN != "" && !lastFourSSN(c.SSN) && !validSSN(c.SSN) {
+		errs.add("InvalidFormat", "/ssn", "ssn must be the last four or full 9 digits.")
+	}
+
+	validateStreet(&errs, "/address1", c.Address1)
+	validateStreet(&errs, "/address2", c.Address2)
+
+	if c.State != "" && !usStates[c.State] {
+		errs.add("InvalidFormat", "/state", "state must be a two-letter U.S. state code.")
+	}
+
+	if c.PostalCode != "" && !postalCodePattern.MatchString(c.PostalCode) {
+		errs.add("InvalidFormat", "/postalCode", "postalCode must be a 5 or 9 digit ZIP code.")
+	}
+
+	if c.Controller != nil {
+		c.Controller.validate(&errs, "/controller")
+	}
+
+	return errs.err()
+}
+
+// Validate checks the supplied controller fields against dwolla's
+// documented rules
+func (c *ControllerRequest) Validate() error {
+	var errs validationErrors
+
+	c.validate(&errs, "")
+
+	return errs.err()
+}
+
+// validate records the controller's errors with paths under the prefix
+//
+// Controllers with a U.S. address are verified with the last four or full
+// SSN; all other controllers must supply a passport.
+func (c *ControllerRequest) validate(errs *validationErrors, prefix string) {
+	if c.DateOfBirth != "" {
+		validateDateOfBirth(errs, prefix+"/dateOfBirth", c.DateOfBirth)
+	}
+
+	if c.SSN != "" && !lastFourSSN(c.SSN) && !validSSN(c.SSN) {
+		errs.add("InvalidFormat", prefix+"/ssn", "ssn must be the last four or full 9 digits.")
+	}
+
+	c.Address.validate(errs, prefix+"/address")
+
+	validatePassport(errs, prefix, c.Address.Country, c.Passport)
+}
+
+// Validate checks the supplied beneficial owner fields against dwolla's
+// documented rules
+func (b *BeneficialOwnerRequest) Validate() error {
+	var errs validationErrors
+
+	if b.DateOfBirth != "" {
+		validateDateOfBirth(&errs, "/dateOfBirth", b.DateOfBirth)
+	}
+
+	if b.SSN != "" && !validSSN(b.SSN) {
+		errs.add("InvalidFormat", "/ssn", "ssn must be the full 9 digits.")
+	}
+
+	b.Address.validate(&errs, "/address")
+
+	validatePassport(&errs, "", b.Address.Country, b.Passport)
+
+	return errs.err()
+}
+
+// Validate checks the supplied address fields against dwolla's documented
+// rules
+func (a Address) Validate() error {
+	var errs validationErrors
+
+	a.validate(&errs, "")
+
+	return errs.err()
+}
+
+// validate records the address's errors with paths under the prefix
+//
+// The state and postal code are only checked for U.S. addresses.
+func (a Address) validate(errs *validationErrors, prefix string) {
+	validateStreet(errs, prefix+"/address1", a.Address1)
+	validateStreet(errs, prefix+"/address2", a.Address2)
+	validateStreet(errs, prefix+"/address3", a.Address3)
+
+	if a.Country != "" && !countryPattern.MatchString(a.Country) {
+		errs.add("InvalidFormat", prefix+"/country", "country must be a two-letter ISO country code.")
+	}
+
+	if a.Country != "US" {
+		return
+	}
+
+	if a.StateProvinceRegion != "" && !usStates[a.StateProvinceRegion] {
+		errs.add("InvalidFormat", prefix+"/stateProvinceRegion", "stateProvinceRegion must be a two-letter U.S. state code.")
+	}
+
+	if a.PostalCode != "" && !postalCodePattern.MatchString(a.PostalCode) {
+		errs.add("InvalidFormat", prefix+"/postalCode", "postalCode must be a 5 or 9 digit ZIP code.")
+	}
+}
+
+// validateDateOfBirth records an error if the date is malformed, in the
+// future or younger than MinimumAge
+func validateDateOfBirth(errs *validationErrors, path, date string) {
+	dob, err := time.Parse(ListDateFormat, date)
+	if err != nil {
+		errs.add("InvalidFormat", path, "dateOfBirth must be formatted as YYYY-MM-DD.")
+		return
+	}
+
+	now := time.Now().UTC()
+
+	if dob.After(now) {
+		errs.add("Invalid", path, "dateOfBirth must not be in the future.")
+		return
+	}
+
+	if dob.AddDate(MinimumAge, 0, 0).After(now) {
+		errs.add("Invalid", path, "Must be at least 18 years of age.")
+	}
+}
+
+// validatePassport records an error if a non-U.S. person is missing their
+// passport or the passport is incomplete
+func validatePassport(errs *validationErrors, prefix, country string, passport *Passport) {
+	if passport == nil {
+		if country != "" && country != "US" {
+			errs.add("Required", prefix+"/passport", "passport required for non-U.S. persons.")
+		}
+
+		return
+	}
+
+	if passport.Number == "" {
+		errs.add("Required", prefix+"/passport/number", "passport number required.")
+	}
+
+	if passport.Country == "" {
+		errs.add("Required", prefix+"/passport/country", "passport country required.")
+	} else if !countryPattern.MatchString(passport.Country) {
+		errs.add("InvalidFormat", prefix+"/passport/country", "passport country must be a two-letter ISO country code.")
+	}
+}
+
+// validateStreet records an error if the street address is a PO box
+func validateStreet(errs *validationErrors, path, street string) {
+	if poBoxPattern.MatchString(street) {
+		errs.add("Invalid", path, "PO Boxes are not allowed.")
+	}
+}
+
+// lastFourSSN returns true if the ssn is exactly 4 digits
+func lastFourSSN(ssn string) bool {
+	return len(ssn) == 4 && allDigits(ssn)
+}
+
+// validSSN returns true if the ssn is a full 9-digit SSN that could have
+// been issued
+//
+// SSNs are never issued with an area of 000, 666 or 900-999, a group of 00
+// or a serial of 0000.
+func validSSN(ssn string) bool {
+	if !fullSSN(ssn) {
+		return false
+	}
+
+	digits := strings.Replace(ssn, "-", "", -1)
+	area, group, serial := digits[:3], digits[3:5], digits[5:]
+
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// allDigits returns true if s is non-empty and only contains digits
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
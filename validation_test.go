@@ -0,0 +1,160 @@
+package dwolla
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomerRequestValidate(t *testing.T) {
+	req := &CustomerRequest{
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       "janedoe@nomail.com",
+		Type:        CustomerTypePersonal,
+		DateOfBirth: "1970-01-01",
+		SSN:         "1234",
+		Address1:    "99-99 33rd St",
+		City:        "Some City",
+		State:       "NY",
+		PostalCode:  "11101-1234",
+	}
+
+	assert.Nil(t, req.Validate())
+
+	req.SSN = "123-45-6789"
+	assert.Nil(t, req.Validate())
+
+	req.SSN = "000-45-6789"
+	req.DateOfBirth = time.Now().AddDate(-17, 0, 0).Format(ListDateFormat)
+	req.Address1 = "P.O. Box 123"
+	req.State = "ZZ"
+	req.PostalCode = "1110"
+	req.BusinessType = BusinessTypeLLC
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/businessType", "/dateOfBirth", "/ssn", "/address1", "/state", "/postalCode"})
+
+	req = &CustomerRequest{Type: CustomerTypeReceiveOnly, SSN: "1234", DateOfBirth: "1970-01-01", Controller: &ControllerRequest{}}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/ssn", "/dateOfBirth", "/controller"})
+}
+
+func TestCustomerRequestValidateController(t *testing.T) {
+	req := &CustomerRequest{
+		Type: CustomerTypeBusiness,
+		Controller: &ControllerRequest{
+			FirstName:   "John",
+			LastName:    "Controller",
+			DateOfBirth: "1980-31-01",
+			Address:     Address{Address1: "PO Box 99", City: "Toronto", Country: "CA"},
+		},
+	}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/controller/dateOfBirth", "/controller/address/address1", "/controller/passport"})
+
+	req.Controller.DateOfBirth = "1980-01-31"
+	req.Controller.Address.Address1 = "1 Yonge St"
+	req.Controller.Passport = &Passport{Number: "JKL1234"}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/controller/passport/country"})
+
+	req.Controller.Passport.Country = "CA"
+
+	assert.Nil(t, req.Validate())
+}
+
+func TestControllerRequestValidate(t *testing.T) {
+	req := &ControllerRequest{
+		SSN:         "12345",
+		DateOfBirth: time.Now().AddDate(0, 0, 1).Format(ListDateFormat),
+		Address:     Address{StateProvinceRegion: "IA", PostalCode: "50266", Country: "US"},
+	}
+
+	err := req.Validate()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/dateOfBirth", "/ssn"})
+	assert.Equal(t, err.(ValidationError).Embedded["errors"][0].Message, "dateOfBirth must not be in the future.")
+}
+
+func TestBeneficialOwnerRequestValidate(t *testing.T) {
+	req := &BeneficialOwnerRequest{
+		FirstName:   "beneficial",
+		LastName:    "owner",
+		DateOfBirth: "1980-01-01",
+		SSN:         "555-55-5555",
+		Address: Address{
+			Address1:            "123 Main St.",
+			City:                "Des Moines",
+			StateProvinceRegion: "IA",
+			Country:             "US",
+			PostalCode:          "50309",
+		},
+	}
+
+	assert.Nil(t, req.Validate())
+
+	req.SSN = "5555"
+	req.Address.StateProvinceRegion = "Iowa"
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/ssn", "/address/stateProvinceRegion"})
+
+	req = &BeneficialOwnerRequest{Address: Address{Country: "Canada"}}
+
+	assert.Equal(t, validationErrorPaths(req.Validate()), []string{"/address/country", "/passport"})
+}
+
+func TestAddressValidate(t *testing.T) {
+	assert.Nil(t, Address{Address1: "1 Post Office Square", StateProvinceRegion: "MA", PostalCode: "02109", Country: "US"}.Validate())
+	assert.Nil(t, Address{Address1: "10 Downing St", StateProvinceRegion: "London", PostalCode: "SW1A 2AA", Country: "GB"}.Validate())
+
+	err := Address{Address2: "post office box 5", StateProvinceRegion: "XX", PostalCode: "ABCDE", Country: "US"}.Validate()
+
+	assert.Equal(t, validationErrorPaths(err), []string{"/address2", "/stateProvinceRegion", "/postalCode"})
+}
+
+func TestValidSSN(t *testing.T) {
+	assert.True(t, validSSN("123-45-6789"))
+	assert.True(t, validSSN("123456789"))
+	assert.False(t, validSSN("666-45-6789"))
+	assert.False(t, validSSN("923-45-6789"))
+	assert.False(t, validSSN("123-00-6789"))
+	assert.False(t, validSSN("123-45-0000"))
+	assert.False(t, validSSN("1234"))
+}
+
+func TestClientValidate(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "customer.json"))
+	req := &CustomerRequest{Type: CustomerTypePersonal, State: "ZZ"}
+
+	res, err := c.Customer.Create(ctx, req)
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Nil(t, res)
+
+	res, err = c.Customer.Update(ctx, "FC451A7A-AE30-4404-AB95-E3553FCD733F", req)
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Nil(t, res)
+
+	c.DisableValidation = true
+	res, err = c.Customer.Create(ctx, req)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+}
+
+func TestBeneficialOwnerUpdateValidate(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "beneficial-owner.json"))
+
+	owner := &BeneficialOwner{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/beneficial-owners/07d59716-ef22-4fe6-98e8-f3190233dfb8"}}}}
+	err := owner.Update(ctx, &BeneficialOwnerRequest{SSN: "1234"})
+
+	assert.IsType(t, ValidationError{}, err)
+
+	res, err := c.BeneficialOwner.Update(ctx, "07d59716-ef22-4fe6-98e8-f3190233dfb8", &BeneficialOwnerRequest{SSN: "1234"})
+
+	assert.IsType(t, ValidationError{}, err)
+	assert.Nil(t, res)
+}
@@ -2,8 +2,19 @@ package dwolla
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader is the header containing the webhook signature
+	WebhookSignatureHeader = "X-Request-Signature-SHA-256"
+	// WebhookTopicHeader is the header containing the webhook topic
+	WebhookTopicHeader = "X-Dwolla-Topic"
 )
 
 // WebhookService is the webhook service interface
@@ -65,6 +76,18 @@ type WebhookResponse struct {
 	Body       string          `json:"body"`
 }
 
+// WebhookHealthReport summarizes webhook delivery attempts
+type WebhookHealthReport struct {
+	Webhooks       int
+	Attempts       int
+	Failures       int
+	FailureRate    float64
+	AverageLatency time.Duration
+	MaxLatency     time.Duration
+	StatusCodes    map[int]int
+	LastSuccess    time.Time
+}
+
 // WebhookRetry is a webhook retry
 type WebhookRetry struct {
 	Resource
@@ -79,6 +102,90 @@ type WebhookRetries struct {
 	Total    int                       `json:"total"`
 }
 
+// NewWebhookHealthReport builds a health report from webhook attempts
+func NewWebhookHealthReport(webhooks []Webhook) *WebhookHealthReport {
+	var totalLatency time.Duration
+
+	report := &WebhookHealthReport{
+		Webhooks:    len(webhooks),
+		StatusCodes: map[int]int{},
+	}
+
+	for _, webhook := range webhooks {
+		for _, attempt := range webhook.Attempts {
+			report.Attempts++
+			report.StatusCodes[attempt.Response.StatusCode]++
+
+			if !attempt.Succeeded() {
+				report.Failures++
+			} else if t := attempt.RequestTime(); t.After(report.LastSuccess) {
+				report.LastSuccess = t
+			}
+
+			latency := attempt.Latency()
+			totalLatency += latency
+
+			if latency > report.MaxLatency {
+				report.MaxLatency = latency
+			}
+		}
+	}
+
+	if report.Attempts > 0 {
+		report.FailureRate = float64(report.Failures) / float64(report.Attempts)
+		report.AverageLatency = totalLatency / time.Duration(report.Attempts)
+	}
+
+	return report
+}
+
+// Latency returns the time between the attempt's request and response
+func (a WebhookAttempt) Latency() time.Duration {
+	request := a.RequestTime()
+	response, _ := time.Parse(time.RFC3339, a.Response.Timestamp)
+
+	if request.IsZero() || response.IsZero() {
+		return 0
+	}
+
+	return response.Sub(request)
+}
+
+// RequestTime returns the attempt's request timestamp as time.Time
+func (a WebhookAttempt) RequestTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, a.Request.Timestamp)
+	return t
+}
+
+// Succeeded returns true if the attempt received a 2xx response
+func (a WebhookAttempt) Succeeded() bool {
+	return a.Response.StatusCode >= 200 && a.Response.StatusCode <= 299
+}
+
+// Delivered returns true if any attempt of the webhook succeeded
+func (w Webhook) Delivered() bool {
+	for _, attempt := range w.Attempts {
+		if attempt.Succeeded() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FirstAttemptTime returns the time of the webhook's earliest attempt
+func (w Webhook) FirstAttemptTime() time.Time {
+	var first time.Time
+
+	for _, attempt := range w.Attempts {
+		if t := attempt.RequestTime(); first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+
+	return first
+}
+
 // Retrieve retrieves the webhook with matching id
 //
 // see: https://docsv2.dwolla.com/#retrieve-a-webhook
@@ -153,3 +260,31 @@ func (w *Webhook) Retry(ctx context.Context) (*WebhookRetry, error) {
 
 	return &retry, nil
 }
+
+// ErrInvalidWebhookSignature is returned when a webhook signature does not
+// match its body
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// SignWebhook returns the signature of a webhook body for the secret
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature returns true if the signature matches the webhook
+// body for the secret
+//
+// see: https://developers.dwolla.com/guides/webhooks/validating-webhooks
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
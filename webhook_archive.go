@@ -0,0 +1,343 @@
+package dwolla
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchivedWebhook is a raw webhook request as it was received
+//
+// Verified records whether the request's signature was valid when it was
+// received.
+type ArchivedWebhook struct {
+	ID         string      `json:"id"`
+	Topic      EventTopic  `json:"topic"`
+	ReceivedAt time.Time   `json:"receivedAt"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"`
+	Verified   bool        `json:"verified"`
+}
+
+// NewArchivedWebhook creates an archived webhook from a received request
+// and its raw body
+func NewArchivedWebhook(r *http.Request, body []byte) *ArchivedWebhook {
+	var event Event
+
+	// The body is archived even when it isn't a valid event so that it can
+	// be inspected later.
+	json.Unmarshal(body, &event)
+
+	topic := event.Topic
+	if topic == "" {
+		topic = EventTopic(r.Header.Get(WebhookTopicHeader))
+	}
+
+	return &ArchivedWebhook{
+		ID:         event.ID,
+		Topic:      topic,
+		ReceivedAt: time.Now().UTC(),
+		Headers:    r.Header.Clone(),
+		Body:       body,
+	}
+}
+
+// WebhookArchiveFilter selects archived webhooks
+type WebhookArchiveFilter struct {
+	Since  time.Time
+	Until  time.Time
+	Topics []EventTopic
+}
+
+// Match returns true if the archived webhook matches the filter
+func (f WebhookArchiveFilter) Match(w *ArchivedWebhook) bool {
+	if !f.Since.IsZero() && w.ReceivedAt.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && w.ReceivedAt.After(f.Until) {
+		return false
+	}
+
+	if len(f.Topics) == 0 {
+		return true
+	}
+
+	for _, topic := range f.Topics {
+		if topic == w.Topic {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookArchive stores raw webhook requests
+type WebhookArchive interface {
+	Store(context.Context, *ArchivedWebhook) error
+	List(context.Context, WebhookArchiveFilter) ([]*ArchivedWebhook, error)
+}
+
+// MemoryWebhookArchive is an in-memory webhook archive
+type MemoryWebhookArchive struct {
+	mu       sync.Mutex
+	webhooks []*ArchivedWebhook
+}
+
+// Store archives the webhook
+func (m *MemoryWebhookArchive) Store(ctx context.Context, w *ArchivedWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.webhooks = append(m.webhooks, w)
+
+	return nil
+}
+
+// List returns archived webhooks matching the filter, oldest first
+func (m *MemoryWebhookArchive) List(ctx context.Context, filter WebhookArchiveFilter) ([]*ArchivedWebhook, error) {
+	var webhooks []*ArchivedWebhook
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, w := range m.webhooks {
+		if filter.Match(w) {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	sortArchivedWebhooks(webhooks)
+
+	return webhooks, nil
+}
+
+// FileWebhookArchive is a webhook archive that stores each webhook as a
+// json file in a directory
+type FileWebhookArchive struct {
+	Dir string
+}
+
+// Store archives the webhook
+func (f *FileWebhookArchive) Store(ctx context.Context, w *ArchivedWebhook) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.json", w.ReceivedAt.UnixNano(), strings.Replace(w.ID, string(filepath.Separator), "", -1))
+
+	return ioutil.WriteFile(filepath.Join(f.Dir, name), data, 0600)
+}
+
+// List returns archived webhooks matching the filter, oldest first
+func (f *FileWebhookArchive) List(ctx context.Context, filter WebhookArchiveFilter) ([]*ArchivedWebhook, error) {
+	var webhooks []*ArchivedWebhook
+
+	files, err := filepath.Glob(filepath.Join(f.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var w ArchivedWebhook
+
+		if err := json.Unmarshal(data, &w); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+
+		if filter.Match(&w) {
+			webhooks = append(webhooks, &w)
+		}
+	}
+
+	sortArchivedWebhooks(webhooks)
+
+	return webhooks, nil
+}
+
+// sortArchivedWebhooks sorts archived webhooks oldest first
+func sortArchivedWebhooks(webhooks []*ArchivedWebhook) {
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].ReceivedAt.Before(webhooks[j].ReceivedAt)
+	})
+}
+
+// ArchiveWebhooks wraps a webhook handler, archiving each signed request
+// before the handler runs
+//
+// Bodies larger than DefaultWebhookQueueMaxBodySize are rejected with a 413
+// and requests whose signature does not verify with the secret are
+// rejected with a 401, so neither is archived or reaches the handler.
+func ArchiveWebhooks(archive WebhookArchive, secret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, DefaultWebhookQueueMaxBodySize))
+		if err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r.Body.Close()
+
+		if !VerifyWebhookSignature(secret, body, r.Header.Get(WebhookSignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		webhook := NewArchivedWebhook(r, body)
+		webhook.Verified = true
+
+		if err := archive.Store(r.Context(), webhook); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WebhookReplayer re-runs archived webhooks
+//
+// When Secret is set, each payload is re-signed with it so that it passes
+// signature verification on an endpoint using a different secret, such as
+// staging. Only webhooks whose signature was verified when they were
+// archived are re-signed.
+type WebhookReplayer struct {
+	Archive WebhookArchive
+	Secret  string
+}
+
+// Replay runs archived webhooks matching the filter through the handler,
+// oldest first, and returns the number replayed
+//
+// Replay stops at the first webhook the handler does not accept with a 2xx
+// response.
+func (r *WebhookReplayer) Replay(ctx context.Context, filter WebhookArchiveFilter, handler http.Handler) (int, error) {
+	return r.replay(ctx, filter, func(req *http.Request) (int, error) {
+		res := &replayResponseWriter{header: http.Header{}}
+		handler.ServeHTTP(res, req)
+
+		return res.status(), nil
+	})
+}
+
+// ReplayTo sends archived webhooks matching the filter to the url, oldest
+// first, and returns the number replayed
+//
+// Replay stops at the first webhook the endpoint does not accept with a 2xx
+// response.
+func (r *WebhookReplayer) ReplayTo(ctx context.Context, filter WebhookArchiveFilter, url string, client HTTPClient) (int, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return r.replay(ctx, filter, func(req *http.Request) (int, error) {
+		target, err := http.NewRequestWithContext(ctx, "POST", url, req.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		target.Header = req.Header
+
+		res, err := client.Do(target)
+		if err != nil {
+			return 0, err
+		}
+
+		defer res.Body.Close()
+
+		return res.StatusCode, nil
+	})
+}
+
+// replay builds a request for each matching archived webhook and sends it
+func (r *WebhookReplayer) replay(ctx context.Context, filter WebhookArchiveFilter, send func(*http.Request) (int, error)) (int, error) {
+	webhooks, err := r.Archive.List(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, w := range webhooks {
+		req, err := http.NewRequestWithContext(ctx, "POST", "/", bytes.NewReader(w.Body))
+		if err != nil {
+			return i, err
+		}
+
+		req.Header = w.Headers.Clone()
+		if req.Header == nil {
+			req.Header = http.Header{}
+		}
+
+		if r.Secret != "" {
+			if !w.Verified {
+				return i, fmt.Errorf("Webhook %s was not verified when archived and will not be re-signed", w.ID)
+			}
+
+			req.Header.Set(WebhookSignatureHeader, SignWebhook(r.Secret, w.Body))
+		}
+
+		status, err := send(req)
+		if err != nil {
+			return i, err
+		}
+
+		if status < 200 || status > 299 {
+			return i, fmt.Errorf("Replay of webhook %s failed with status %d", w.ID, status)
+		}
+	}
+
+	return len(webhooks), nil
+}
+
+// replayResponseWriter captures the status code written by a handler
+type replayResponseWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *replayResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *replayResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return len(b), nil
+}
+
+func (w *replayResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// status returns the written status code, defaulting to 200
+func (w *replayResponseWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
@@ -0,0 +1,171 @@
+package dwolla
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveWebhooks(t *testing.T) {
+	archive := &MemoryWebhookArchive{}
+
+	var received []byte
+
+	handler := ArchiveWebhooks(archive, "secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+	}))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestWebhookRequest("secret", testWebhookBody))
+
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Equal(t, string(received), testWebhookBody)
+
+	webhooks, err := archive.List(ctx, WebhookArchiveFilter{})
+
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 1)
+	assert.Equal(t, webhooks[0].ID, "03c7e14c-7f15-44a2-bcf7-83f2f7e95d50")
+	assert.Equal(t, webhooks[0].Topic, EventTopicTransferCreated)
+	assert.Equal(t, string(webhooks[0].Body), testWebhookBody)
+	assert.True(t, webhooks[0].Verified)
+}
+
+func TestArchiveWebhooksUnverified(t *testing.T) {
+	archive := &MemoryWebhookArchive{}
+	handler := ArchiveWebhooks(archive, "secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unverified webhook was handled")
+	}))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestWebhookRequest("forged", testWebhookBody))
+
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	webhooks, err := archive.List(ctx, WebhookArchiveFilter{})
+
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 0)
+
+	assert.Nil(t, archive.Store(ctx, NewArchivedWebhook(newTestWebhookRequest("forged", testWebhookBody), []byte(testWebhookBody))))
+
+	replayer := &WebhookReplayer{Archive: archive, Secret: "staging"}
+	count, err := replayer.Replay(ctx, WebhookArchiveFilter{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unverified webhook was replayed")
+	}))
+
+	assert.Error(t, err)
+	assert.Equal(t, count, 0)
+}
+
+func TestArchiveWebhooksTooLarge(t *testing.T) {
+	archive := &MemoryWebhookArchive{}
+	handler := ArchiveWebhooks(archive, "secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("oversized webhook was handled")
+	}))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newTestWebhookRequest("secret", strings.Repeat("x", DefaultWebhookQueueMaxBodySize+1)))
+
+	assert.Equal(t, res.Code, http.StatusRequestEntityTooLarge)
+
+	webhooks, err := archive.List(ctx, WebhookArchiveFilter{})
+
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 0)
+}
+
+func TestWebhookArchiveFilter(t *testing.T) {
+	now := time.Now()
+	w := &ArchivedWebhook{Topic: EventTopicTransferCreated, ReceivedAt: now}
+
+	assert.True(t, WebhookArchiveFilter{}.Match(w))
+	assert.True(t, WebhookArchiveFilter{Since: now.Add(-time.Hour), Until: now.Add(time.Hour)}.Match(w))
+	assert.False(t, WebhookArchiveFilter{Since: now.Add(time.Hour)}.Match(w))
+	assert.False(t, WebhookArchiveFilter{Until: now.Add(-time.Hour)}.Match(w))
+	assert.True(t, WebhookArchiveFilter{Topics: []EventTopic{EventTopicTransferCreated}}.Match(w))
+	assert.False(t, WebhookArchiveFilter{Topics: []EventTopic{EventTopicCustomerCreated}}.Match(w))
+}
+
+func TestFileWebhookArchive(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "dwolla")
+	defer os.RemoveAll(dir)
+
+	archive := &FileWebhookArchive{Dir: dir}
+
+	first := NewArchivedWebhook(newTestWebhookRequest("secret", testWebhookBody), []byte(testWebhookBody))
+	second := &ArchivedWebhook{ID: "second", Topic: EventTopicCustomerCreated, ReceivedAt: first.ReceivedAt.Add(time.Second), Body: []byte("{}")}
+
+	assert.Nil(t, archive.Store(ctx, second))
+	assert.Nil(t, archive.Store(ctx, first))
+
+	webhooks, err := archive.List(ctx, WebhookArchiveFilter{})
+
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 2)
+	assert.Equal(t, webhooks[0].ID, first.ID)
+	assert.Equal(t, webhooks[0].Headers.Get(WebhookSignatureHeader), first.Headers.Get(WebhookSignatureHeader))
+	assert.Equal(t, webhooks[1].ID, "second")
+
+	webhooks, err = archive.List(ctx, WebhookArchiveFilter{Topics: []EventTopic{EventTopicCustomerCreated}})
+
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 1)
+}
+
+func TestWebhookReplayerReplay(t *testing.T) {
+	archived := NewArchivedWebhook(newTestWebhookRequest("production", testWebhookBody), []byte(testWebhookBody))
+	archived.Verified = true
+
+	archive := &MemoryWebhookArchive{}
+	archive.Store(ctx, archived)
+
+	var topics []EventTopic
+
+	q := NewWebhookQueue(nil, "staging", func(ctx context.Context, e *Event) error {
+		topics = append(topics, e.Topic)
+		return nil
+	})
+
+	replayer := &WebhookReplayer{Archive: archive}
+
+	count, err := replayer.Replay(ctx, WebhookArchiveFilter{}, q)
+
+	assert.Error(t, err)
+	assert.Equal(t, count, 0)
+
+	replayer.Secret = "staging"
+	count, err = replayer.Replay(ctx, WebhookArchiveFilter{}, q)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 1)
+	assert.Nil(t, q.Shutdown(ctx))
+	assert.Equal(t, topics, []EventTopic{EventTopicTransferCreated})
+}
+
+func TestWebhookReplayerReplayTo(t *testing.T) {
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	archive := &MemoryWebhookArchive{}
+	archive.Store(ctx, &ArchivedWebhook{ID: "1", ReceivedAt: time.Now(), Body: []byte(testWebhookBody), Verified: true})
+
+	replayer := &WebhookReplayer{Archive: archive, Secret: "staging"}
+	count, err := replayer.ReplayTo(ctx, WebhookArchiveFilter{}, server.URL, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 1)
+	assert.True(t, bytes.Equal(body, []byte(testWebhookBody)))
+}
@@ -0,0 +1,370 @@
+package dwolla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWebhookQueueWorkers is the default number of webhook workers
+	DefaultWebhookQueueWorkers = 4
+	// DefaultWebhookQueueSize is the default number of queued webhooks
+	DefaultWebhookQueueSize = 100
+	// DefaultWebhookQueueMaxAttempts is the default number of times a
+	// webhook is handled before it is dead-lettered
+	DefaultWebhookQueueMaxAttempts = 5
+	// DefaultWebhookQueueMaxBodySize is the default maximum webhook body size
+	DefaultWebhookQueueMaxBodySize = 1 << 20
+)
+
+// ErrWebhookQueueClosed is returned when a webhook arrives after shutdown
+var ErrWebhookQueueClosed = errors.New("webhook queue is closed")
+
+// ErrWebhookQueueFull is returned when the webhook queue has no capacity
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+// WebhookDeadLetterSink receives webhooks that could not be handled
+type WebhookDeadLetterSink interface {
+	DeadLetter(ctx context.Context, event *Event, body []byte, err error) error
+}
+
+// WebhookBackoff returns the delay before the given handling attempt
+type WebhookBackoff func(attempt int) time.Duration
+
+// ExponentialWebhookBackoff doubles the delay after each attempt
+func ExponentialWebhookBackoff(base, max time.Duration) WebhookBackoff {
+	return func(attempt int) time.Duration {
+		delay := base
+
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+
+		if delay > max {
+			delay = max
+		}
+
+		return delay
+	}
+}
+
+// webhookJob is a queued webhook
+type webhookJob struct {
+	event   *Event
+	body    []byte
+	attempt int
+}
+
+// WebhookQueue acknowledges dwolla webhooks immediately and handles them
+// asynchronously
+//
+// The queue verifies each webhook's signature and responds before the
+// handler runs, so slow handlers never cause dwolla to retry or pause the
+// subscription. Handlers that fail, or panic, are retried with backoff,
+// then sent to the dead-letter sink. Retries wait outside the workers, so
+// failing webhooks never stop new ones from being handled. When the queue
+// is full, webhooks are rejected with a 503 so dwolla delivers them again
+// later.
+//
+// OnError, when set, is called when a webhook is dead-lettered without a
+// sink or the sink fails to store it.
+type WebhookQueue struct {
+	Client      *Client
+	Secret      string
+	Handler     EventHandler
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	MaxBodySize int64
+	Backoff     WebhookBackoff
+	DeadLetter  WebhookDeadLetterSink
+	OnError     func(error)
+
+	mu          sync.RWMutex
+	jobs        chan webhookJob
+	retries     chan webhookJob
+	done        chan struct{}
+	closed      bool
+	startOnce   sync.Once
+	wg          sync.WaitGroup
+	outstanding sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewWebhookQueue initializes a new webhook queue
+func NewWebhookQueue(client *Client, secret string, handler EventHandler) *WebhookQueue {
+	return &WebhookQueue{
+		Client:      client,
+		Secret:      secret,
+		Handler:     handler,
+		Workers:     DefaultWebhookQueueWorkers,
+		QueueSize:   DefaultWebhookQueueSize,
+		MaxAttempts: DefaultWebhookQueueMaxAttempts,
+		MaxBodySize: DefaultWebhookQueueMaxBodySize,
+		Backoff:     ExponentialWebhookBackoff(time.Second, time.Minute),
+	}
+}
+
+// Start starts the webhook workers
+func (q *WebhookQueue) Start() {
+	q.startOnce.Do(func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.ctx, q.cancel = context.WithCancel(context.Background())
+
+		if q.jobs == nil {
+			q.jobs = make(chan webhookJob, q.queueSize())
+		}
+
+		q.retries = make(chan webhookJob)
+		q.done = make(chan struct{})
+
+		workers := q.Workers
+		if workers <= 0 {
+			workers = DefaultWebhookQueueWorkers
+		}
+
+		for i := 0; i < workers; i++ {
+			q.wg.Add(1)
+			go q.work()
+		}
+	})
+}
+
+// Enqueue verifies a raw webhook and queues it for handling
+func (q *WebhookQueue) Enqueue(body []byte, signature string) error {
+	if !VerifyWebhookSignature(q.Secret, body, signature) {
+		return ErrInvalidWebhookSignature
+	}
+
+	var event Event
+
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	event.client = q.Client
+
+	q.Start()
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrWebhookQueueClosed
+	}
+
+	q.outstanding.Add(1)
+
+	select {
+	case q.jobs <- webhookJob{event: &event, body: body, attempt: 1}:
+		return nil
+	default:
+		q.outstanding.Done()
+		return ErrWebhookQueueFull
+	}
+}
+
+// ServeHTTP receives a webhook and acknowledges it once queued
+func (q *WebhookQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	maxBodySize := q.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultWebhookQueueMaxBodySize
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	switch err := q.Enqueue(body, r.Header.Get(WebhookSignatureHeader)); err {
+	case nil:
+		w.WriteHeader(http.StatusOK)
+	case ErrWebhookQueueClosed, ErrWebhookQueueFull:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// Shutdown stops accepting webhooks and waits for queued webhooks, and
+// their retries, to be handled
+//
+// If the context expires first, in-flight handlers are cancelled and every
+// webhook still queued or waiting to be retried is dead-lettered with the
+// cancellation error, without running its handler. Shutdown waits for that
+// to finish and returns the context's error.
+func (q *WebhookQueue) Shutdown(ctx context.Context) error {
+	q.Start()
+
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	finished := make(chan struct{})
+
+	go func() {
+		q.outstanding.Wait()
+		close(finished)
+	}()
+
+	var err error
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		err = ctx.Err()
+		q.cancel()
+		<-finished
+	}
+
+	q.stop()
+
+	return err
+}
+
+// stop stops the workers once every webhook has been handled
+func (q *WebhookQueue) stop() {
+	q.mu.Lock()
+	select {
+	case <-q.done:
+	default:
+		close(q.done)
+	}
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	q.cancel()
+}
+
+// queueSize returns the configured queue size
+func (q *WebhookQueue) queueSize() int {
+	if q.QueueSize <= 0 {
+		return DefaultWebhookQueueSize
+	}
+
+	return q.QueueSize
+}
+
+// work handles queued webhooks and retries until the queue is stopped
+func (q *WebhookQueue) work() {
+	defer q.wg.Done()
+
+	jobs := q.jobs
+
+	for {
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				jobs = nil
+				continue
+			}
+
+			q.handle(job)
+		case job := <-q.retries:
+			q.handle(job)
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// handle runs the handler for a webhook once, scheduling a retry or
+// dead-lettering it when the handler fails
+func (q *WebhookQueue) handle(job webhookJob) {
+	if err := q.ctx.Err(); err != nil {
+		q.deadLetter(job, err)
+		return
+	}
+
+	err := q.run(job)
+	if err == nil {
+		q.outstanding.Done()
+		return
+	}
+
+	maxAttempts := q.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookQueueMaxAttempts
+	}
+
+	if job.attempt >= maxAttempts || q.ctx.Err() != nil {
+		q.deadLetter(job, err)
+		return
+	}
+
+	go q.retry(job)
+}
+
+// run runs the handler, turning a panic into an error
+func (q *WebhookQueue) run(job webhookJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Webhook handler panicked: %v", r)
+		}
+	}()
+
+	return q.Handler(q.ctx, job.event)
+}
+
+// retry waits for the backoff delay and hands the webhook back to the
+// workers, dead-lettering it if the queue is cancelled first
+func (q *WebhookQueue) retry(job webhookJob) {
+	var delay time.Duration
+	if q.Backoff != nil {
+		delay = q.Backoff(job.attempt)
+	}
+
+	job.attempt++
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-q.ctx.Done():
+		q.deadLetter(job, q.ctx.Err())
+		return
+	}
+
+	select {
+	case q.retries <- job:
+	case <-q.ctx.Done():
+		q.deadLetter(job, q.ctx.Err())
+	}
+}
+
+// deadLetter sends the webhook to the dead-letter sink, reporting the
+// failure through OnError when there is no sink or the sink fails
+func (q *WebhookQueue) deadLetter(job webhookJob, err error) {
+	defer q.outstanding.Done()
+
+	if q.DeadLetter != nil {
+		if sinkErr := q.DeadLetter.DeadLetter(context.Background(), job.event, job.body, err); sinkErr != nil {
+			err = fmt.Errorf("Unable to dead-letter webhook %s: %v", job.event.ID, sinkErr)
+		} else {
+			err = nil
+		}
+	}
+
+	if err != nil && q.OnError != nil {
+		q.OnError(err)
+	}
+}
@@ -0,0 +1,246 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDeadLetterSink struct {
+	mu     sync.Mutex
+	events []*Event
+	errs   []error
+	err    error
+}
+
+func (s *testDeadLetterSink) DeadLetter(ctx context.Context, event *Event, body []byte, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	s.errs = append(s.errs, err)
+
+	return s.err
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	signature := SignWebhook("secret", []byte(testWebhookBody))
+
+	assert.True(t, VerifyWebhookSignature("secret", []byte(testWebhookBody), signature))
+	assert.False(t, VerifyWebhookSignature("other", []byte(testWebhookBody), signature))
+	assert.False(t, VerifyWebhookSignature("secret", []byte(testWebhookBody), "zz"))
+}
+
+func TestWebhookQueueServeHTTP(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		topics []EventTopic
+	)
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		topics = append(topics, e.Topic)
+		return nil
+	})
+
+	res := httptest.NewRecorder()
+	q.ServeHTTP(res, newTestWebhookRequest("secret", testWebhookBody))
+
+	assert.Equal(t, res.Code, http.StatusOK)
+	assert.Nil(t, q.Shutdown(ctx))
+	assert.Equal(t, topics, []EventTopic{EventTopicTransferCreated})
+
+	res = httptest.NewRecorder()
+	q.ServeHTTP(res, newTestWebhookRequest("secret", testWebhookBody))
+
+	assert.Equal(t, res.Code, http.StatusServiceUnavailable)
+}
+
+func TestWebhookQueueServeHTTPInvalidSignature(t *testing.T) {
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		return nil
+	})
+	defer q.Shutdown(ctx)
+
+	res := httptest.NewRecorder()
+	q.ServeHTTP(res, newTestWebhookRequest("wrong", testWebhookBody))
+
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+
+	res = httptest.NewRecorder()
+	q.ServeHTTP(res, httptest.NewRequest("GET", "/webhooks", nil))
+
+	assert.Equal(t, res.Code, http.StatusMethodNotAllowed)
+}
+
+func TestWebhookQueueDeadLetter(t *testing.T) {
+	var attempts int
+
+	sink := &testDeadLetterSink{}
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		attempts++
+		return errors.New("handler failed")
+	})
+	q.Workers = 1
+	q.MaxAttempts = 3
+	q.Backoff = ExponentialWebhookBackoff(time.Millisecond, 2*time.Millisecond)
+	q.DeadLetter = sink
+
+	assert.Nil(t, q.Enqueue([]byte(testWebhookBody), SignWebhook("secret", []byte(testWebhookBody))))
+	assert.Nil(t, q.Shutdown(ctx))
+
+	assert.Equal(t, attempts, 3)
+	assert.Len(t, sink.events, 1)
+	assert.EqualError(t, sink.errs[0], "handler failed")
+}
+
+func TestWebhookQueueRetryDoesNotBlockWorkers(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled []string
+	)
+
+	sink := &testDeadLetterSink{}
+	other := strings.Replace(testWebhookBody, "03c7e14c", "13c7e14c", 1)
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled = append(handled, e.ID)
+
+		if e.ID == "03c7e14c-7f15-44a2-bcf7-83f2f7e95d50" {
+			return errors.New("handler failed")
+		}
+
+		return nil
+	})
+	q.Workers = 1
+	q.MaxAttempts = 2
+	q.Backoff = ExponentialWebhookBackoff(50*time.Millisecond, 50*time.Millisecond)
+	q.DeadLetter = sink
+
+	assert.Nil(t, q.Enqueue([]byte(testWebhookBody), SignWebhook("secret", []byte(testWebhookBody))))
+	assert.Nil(t, q.Enqueue([]byte(other), SignWebhook("secret", []byte(other))))
+	assert.Nil(t, q.Shutdown(ctx))
+
+	assert.Equal(t, []string{"03c7e14c-7f15-44a2-bcf7-83f2f7e95d50", "13c7e14c-7f15-44a2-bcf7-83f2f7e95d50", "03c7e14c-7f15-44a2-bcf7-83f2f7e95d50"}, handled)
+	assert.Len(t, sink.events, 1)
+}
+
+func TestWebhookQueuePanic(t *testing.T) {
+	sink := &testDeadLetterSink{}
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		panic("boom")
+	})
+	q.MaxAttempts = 2
+	q.Backoff = nil
+	q.DeadLetter = sink
+
+	assert.Nil(t, q.Enqueue([]byte(testWebhookBody), SignWebhook("secret", []byte(testWebhookBody))))
+	assert.Nil(t, q.Shutdown(ctx))
+
+	assert.Len(t, sink.events, 1)
+	assert.Contains(t, sink.errs[0].Error(), "boom")
+}
+
+func TestWebhookQueueDeadLetterError(t *testing.T) {
+	var reported []error
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		return errors.New("handler failed")
+	})
+	q.Workers = 1
+	q.MaxAttempts = 1
+	q.DeadLetter = &testDeadLetterSink{err: errors.New("sink failed")}
+	q.OnError = func(err error) {
+		reported = append(reported, err)
+	}
+
+	assert.Nil(t, q.Enqueue([]byte(testWebhookBody), SignWebhook("secret", []byte(testWebhookBody))))
+	assert.Nil(t, q.Shutdown(ctx))
+
+	assert.Len(t, reported, 1)
+	assert.Contains(t, reported[0].Error(), "sink failed")
+}
+
+func TestWebhookQueueShutdownExpired(t *testing.T) {
+	var attempts int32
+
+	started := make(chan struct{})
+	sink := &testDeadLetterSink{}
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			close(started)
+		}
+
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	q.Workers = 1
+	q.DeadLetter = sink
+
+	signature := SignWebhook("secret", []byte(testWebhookBody))
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, q.Enqueue([]byte(testWebhookBody), signature))
+	}
+
+	<-started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, q.Shutdown(shutdownCtx))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	assert.Len(t, sink.events, 3)
+
+	for _, err := range sink.errs {
+		assert.Equal(t, context.Canceled, err)
+	}
+}
+
+func TestWebhookQueueFull(t *testing.T) {
+	release := make(chan struct{})
+
+	q := NewWebhookQueue(nil, "secret", func(ctx context.Context, e *Event) error {
+		<-release
+		return nil
+	})
+	q.Workers = 1
+	q.QueueSize = 1
+
+	signature := SignWebhook("secret", []byte(testWebhookBody))
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = q.Enqueue([]byte(testWebhookBody), signature)
+	}
+
+	assert.Equal(t, err, ErrWebhookQueueFull)
+
+	close(release)
+	assert.Nil(t, q.Shutdown(ctx))
+}
+
+func TestExponentialWebhookBackoff(t *testing.T) {
+	backoff := ExponentialWebhookBackoff(time.Second, 5*time.Second)
+
+	assert.Equal(t, backoff(1), time.Second)
+	assert.Equal(t, backoff(2), 2*time.Second)
+	assert.Equal(t, backoff(3), 4*time.Second)
+	assert.Equal(t, backoff(4), 5*time.Second)
+}
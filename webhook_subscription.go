@@ -4,8 +4,31 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
+const (
+	// WebhookSubscriptionActionCreate creates a missing subscription
+	WebhookSubscriptionActionCreate WebhookSubscriptionAction = "create"
+	// WebhookSubscriptionActionPause pauses a subscription
+	WebhookSubscriptionActionPause WebhookSubscriptionAction = "pause"
+	// WebhookSubscriptionActionRemove removes a stale or duplicate
+	// subscription
+	WebhookSubscriptionActionRemove WebhookSubscriptionAction = "remove"
+	// WebhookSubscriptionActionRotate recreates a subscription with a new
+	// secret
+	WebhookSubscriptionActionRotate WebhookSubscriptionAction = "rotate"
+	// WebhookSubscriptionActionUnpause unpauses a subscription
+	WebhookSubscriptionActionUnpause WebhookSubscriptionAction = "unpause"
+)
+
+// DefaultWebhookHealthCheckInterval is the interval used by
+// UnpauseWhenHealthy when none is given
+const DefaultWebhookHealthCheckInterval = 30 * time.Second
+
 // WebhookSubscriptionService is the webhook subscription service interface
 //
 // see: https://docsv2.dwolla.com/#webhook-subscriptions
@@ -27,6 +50,7 @@ type WebhookSubscription struct {
 	Resource
 	ID      string `json:"id"`
 	URL     string `json:"url"`
+	Paused  bool   `json:"paused"`
 	Created string `json:"created"`
 }
 
@@ -44,6 +68,73 @@ type WebhookSubscriptionRequest struct {
 	Paused bool   `json:"paused"`
 }
 
+// WebhookSubscriptionSpec is the desired state of a webhook subscription
+//
+// Dwolla never returns subscription secrets, so a secret change can not be
+// detected. Set RotateSecret to recreate the subscription with Secret.
+// Rotation is one-shot: once it is applied, EnsureWebhookSubscriptions
+// clears RotateSecret on the spec it was given, so specs loaded from
+// configuration must also drop the flag or every reconcile rotates again.
+type WebhookSubscriptionSpec struct {
+	URL          string
+	Secret       string
+	Paused       bool
+	RotateSecret bool
+}
+
+// WebhookSubscriptionAction is a change made to converge subscriptions
+type WebhookSubscriptionAction string
+
+// WebhookSubscriptionChange is a planned webhook subscription change
+//
+// For a rotation, ID is the replacement subscription once it is created and
+// PreviousID the subscription it replaces.
+type WebhookSubscriptionChange struct {
+	Action     WebhookSubscriptionAction
+	URL        string
+	ID         string
+	PreviousID string
+	Reason     string
+	Applied    bool
+}
+
+// WebhookSubscriptionPlan is the set of changes needed to converge
+// webhook subscriptions
+type WebhookSubscriptionPlan struct {
+	Changes []WebhookSubscriptionChange
+}
+
+// add appends a change to the plan
+func (p *WebhookSubscriptionPlan) add(action WebhookSubscriptionAction, url, id, reason string) {
+	p.Changes = append(p.Changes, WebhookSubscriptionChange{Action: action, URL: url, ID: id, Reason: reason})
+}
+
+// Empty returns true if the plan has no changes
+func (p WebhookSubscriptionPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String returns a printable representation of the plan
+func (p WebhookSubscriptionPlan) String() string {
+	if p.Empty() {
+		return "No webhook subscription changes\n"
+	}
+
+	var b strings.Builder
+
+	for _, change := range p.Changes {
+		fmt.Fprintf(&b, "%-7s %s", change.Action, change.URL)
+
+		if change.ID != "" {
+			fmt.Fprintf(&b, " (%s)", change.ID)
+		}
+
+		fmt.Fprintf(&b, ": %s\n", change.Reason)
+	}
+
+	return b.String()
+}
+
 // Create creates a webhook subscription
 func (w *WebhookSubscriptionServiceOp) Create(ctx context.Context, body *WebhookSubscriptionRequest) (*WebhookSubscription, error) {
 	var subscription WebhookSubscription
@@ -123,6 +214,8 @@ func (w *WebhookSubscription) Unpause(ctx context.Context) error {
 }
 
 // RetrieveWebhooks returns webhooks for this webhook subscription
+//
+// Deprecated: use WebhookSubscription.ListWebhooks
 func (w *Webhook) RetrieveWebhooks(ctx context.Context) (*Webhooks, error) {
 	var webhooks Webhooks
 
@@ -142,3 +235,276 @@ func (w *Webhook) RetrieveWebhooks(ctx context.Context) (*Webhooks, error) {
 
 	return &webhooks, nil
 }
+
+// ListWebhooks returns a page of webhooks for this webhook subscription
+//
+// see: https://docsv2.dwolla.com/#list-webhooks-for-a-webhook-subscription
+func (w *WebhookSubscription) ListWebhooks(ctx context.Context, params *url.Values) (*Webhooks, error) {
+	var webhooks Webhooks
+
+	if _, ok := w.Links["webhooks"]; !ok {
+		return nil, errors.New("No webhooks resource link")
+	}
+
+	if err := w.client.Get(ctx, w.Links["webhooks"].Href, params, nil, &webhooks); err != nil {
+		return nil, err
+	}
+
+	webhooks.client = w.client
+
+	for i := range webhooks.Embedded["webhooks"] {
+		webhooks.Embedded["webhooks"][i].client = w.client
+	}
+
+	return &webhooks, nil
+}
+
+// ListWebhooksBetween returns every webhook for this webhook subscription
+// first attempted within the time range
+//
+// Pages are followed until a page contains only webhooks older than since.
+// A zero since or until leaves that end of the range open.
+func (w *WebhookSubscription) ListWebhooksBetween(ctx context.Context, since, until time.Time) ([]Webhook, error) {
+	var matched []Webhook
+
+	webhooks, err := w.ListWebhooks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		older := 0
+
+		for _, webhook := range webhooks.Embedded["webhooks"] {
+			attempted := webhook.FirstAttemptTime()
+
+			if !since.IsZero() && attempted.Before(since) {
+				older++
+				continue
+			}
+
+			if !until.IsZero() && attempted.After(until) {
+				continue
+			}
+
+			matched = append(matched, webhook)
+		}
+
+		next, ok := webhooks.Links["next"]
+		if !ok || len(webhooks.Embedded["webhooks"]) == 0 || older == len(webhooks.Embedded["webhooks"]) {
+			return matched, nil
+		}
+
+		webhooks = &Webhooks{}
+
+		if err := w.client.Get(ctx, next.Href, nil, nil, webhooks); err != nil {
+			return nil, err
+		}
+
+		for i := range webhooks.Embedded["webhooks"] {
+			webhooks.Embedded["webhooks"][i].client = w.client
+		}
+	}
+}
+
+// RetryFailed retries every webhook since the given time that was never
+// delivered successfully
+func (w *WebhookSubscription) RetryFailed(ctx context.Context, since time.Time) ([]*WebhookRetry, error) {
+	var retries []*WebhookRetry
+
+	webhooks, err := w.ListWebhooksBetween(ctx, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		if webhooks[i].Delivered() {
+			continue
+		}
+
+		retry, err := webhooks[i].Retry(ctx)
+		if err != nil {
+			return retries, err
+		}
+
+		retries = append(retries, retry)
+	}
+
+	return retries, nil
+}
+
+// HealthReport returns a delivery health report for webhooks since the
+// given time
+func (w *WebhookSubscription) HealthReport(ctx context.Context, since time.Time) (*WebhookHealthReport, error) {
+	webhooks, err := w.ListWebhooksBetween(ctx, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWebhookHealthReport(webhooks), nil
+}
+
+// UnpauseWhenHealthy waits until the health check passes, then unpauses the
+// webhook subscription
+//
+// The check is typically a request to the webhook endpoint's own health
+// check. It is retried every interval, DefaultWebhookHealthCheckInterval
+// when zero, until it succeeds or the context is done, in which case the
+// context's error is returned wrapped with the last check's error.
+// Subscriptions that are not paused are left untouched.
+func (w *WebhookSubscription) UnpauseWhenHealthy(ctx context.Context, check func(context.Context) error, interval time.Duration) error {
+	if check == nil {
+		return errors.New("No health check")
+	}
+
+	if !w.Paused {
+		return nil
+	}
+
+	if interval <= 0 {
+		interval = DefaultWebhookHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := check(ctx)
+		if err == nil {
+			return w.Unpause(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Webhook endpoint is unhealthy (%v): %w", err, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnsureWebhookSubscriptions converges the account's webhook subscriptions
+// to the desired specs
+//
+// Subscriptions for URLs that are not desired are removed, as are duplicate
+// subscriptions for the same URL, keeping the oldest. Missing subscriptions
+// are created, subscriptions flagged with RotateSecret are recreated with
+// the new secret, and the paused state is updated to match. When dryRun is
+// true, the plan is returned without making any changes.
+//
+// A rotated subscription's replacement is created before the old
+// subscription is removed, so the URL is never left without one. The
+// desired specs are not modified: a rotation change is Applied once both
+// succeed, and the caller should then clear the spec's RotateSecret so the
+// next run does not rotate again.
+func (c *Client) EnsureWebhookSubscriptions(ctx context.Context, desired []WebhookSubscriptionSpec, dryRun bool) (*WebhookSubscriptionPlan, error) {
+	wanted := map[string]WebhookSubscriptionSpec{}
+
+	for _, spec := range desired {
+		if spec.URL == "" {
+			return nil, errors.New("Webhook subscription spec requires a URL")
+		}
+
+		if _, ok := wanted[spec.URL]; ok {
+			return nil, fmt.Errorf("Duplicate webhook subscription spec for %s", spec.URL)
+		}
+
+		wanted[spec.URL] = spec
+	}
+
+	subscriptions, err := c.WebhookSubscription.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := subscriptions.Embedded["webhook-subscriptions"]
+
+	sort.SliceStable(existing, func(i, j int) bool {
+		return existing[i].Created < existing[j].Created
+	})
+
+	plan := &WebhookSubscriptionPlan{}
+	kept := map[string]bool{}
+	byID := map[string]*WebhookSubscription{}
+
+	for i := range existing {
+		byID[existing[i].ID] = &existing[i]
+	}
+
+	for _, subscription := range existing {
+		spec, ok := wanted[subscription.URL]
+
+		switch {
+		case !ok:
+			plan.add(WebhookSubscriptionActionRemove, subscription.URL, subscription.ID, "not desired")
+		case kept[subscription.URL]:
+			plan.add(WebhookSubscriptionActionRemove, subscription.URL, subscription.ID, "duplicate")
+		case spec.RotateSecret:
+			kept[subscription.URL] = true
+			plan.add(WebhookSubscriptionActionRotate, subscription.URL, subscription.ID, "rotate secret")
+		default:
+			kept[subscription.URL] = true
+
+			if subscription.Paused && !spec.Paused {
+				plan.add(WebhookSubscriptionActionUnpause, subscription.URL, subscription.ID, "paused")
+			} else if !subscription.Paused && spec.Paused {
+				plan.add(WebhookSubscriptionActionPause, subscription.URL, subscription.ID, "not paused")
+			}
+		}
+	}
+
+	for _, spec := range desired {
+		if !kept[spec.URL] {
+			plan.add(WebhookSubscriptionActionCreate, spec.URL, "", "missing")
+		}
+	}
+
+	if dryRun {
+		return plan, nil
+	}
+
+	for i, change := range plan.Changes {
+		spec := wanted[change.URL]
+
+		switch change.Action {
+		case WebhookSubscriptionActionRemove:
+			err = c.WebhookSubscription.Remove(ctx, change.ID)
+		case WebhookSubscriptionActionRotate:
+			plan.Changes[i].PreviousID = change.ID
+
+			if err = c.createWebhookSubscription(ctx, spec, &plan.Changes[i]); err == nil {
+				err = c.WebhookSubscription.Remove(ctx, change.ID)
+			}
+		case WebhookSubscriptionActionCreate:
+			err = c.createWebhookSubscription(ctx, spec, &plan.Changes[i])
+		case WebhookSubscriptionActionPause:
+			err = byID[change.ID].Pause(ctx)
+		case WebhookSubscriptionActionUnpause:
+			err = byID[change.ID].Unpause(ctx)
+		}
+
+		if err != nil {
+			return plan, err
+		}
+
+		plan.Changes[i].Applied = true
+	}
+
+	return plan, nil
+}
+
+// createWebhookSubscription creates a subscription for the spec and records
+// its id on the change
+func (c *Client) createWebhookSubscription(ctx context.Context, spec WebhookSubscriptionSpec, change *WebhookSubscriptionChange) error {
+	subscription, err := c.WebhookSubscription.Create(ctx, &WebhookSubscriptionRequest{
+		URL:    spec.URL,
+		Secret: spec.Secret,
+		Paused: spec.Paused,
+	})
+	if err != nil {
+		return err
+	}
+
+	change.ID = subscription.ID
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package dwolla
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscriptionServiceList(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhook-subscriptions.json"))
+	res, err := c.WebhookSubscription.List(ctx)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, res.Total, 1)
+}
+
+func TestWebhookSubscriptionListWebhooks(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhooks.json"))
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"webhooks": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks"}}}}
+	res, err := subscription.ListWebhooks(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Len(t, res.Embedded["webhooks"], 2)
+}
+
+func TestWebhookSubscriptionListWebhooksError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c}}
+	res, err := subscription.ListWebhooks(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	subscription.Links = Links{"webhooks": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks"}}
+	res, err = subscription.ListWebhooks(ctx, nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestWebhookSubscriptionListWebhooksBetween(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhooks.json"))
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"webhooks": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks"}}}}
+	res, err := subscription.ListWebhooksBetween(ctx, time.Date(2015, 10, 27, 0, 0, 0, 0, time.UTC), time.Time{})
+
+	assert.Nil(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, res[0].ID, "9ece9660-aa34-41eb-80d7-0125d53b45e8")
+}
+
+func TestWebhookSubscriptionRetryFailed(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks": {200, filepath.Join("testdata", "webhooks.json")},
+		"POST /webhooks/9ece9660-aa34-41eb-80d7-0125d53b45e8/retries":              {201, filepath.Join("testdata", "webhook-retry.json")},
+	})
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"webhooks": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks"}}}}
+	res, err := subscription.RetryFailed(ctx, time.Time{})
+
+	assert.Nil(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, res[0].ID, "5aa27a0f-cf99-418d-a3ee-67c0ff99a494")
+	assert.Len(t, mc.requestLog(), 2)
+}
+
+func TestWebhookSubscriptionHealthReport(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhooks.json"))
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"webhooks": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589/webhooks"}}}}
+	res, err := subscription.HealthReport(ctx, time.Time{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, res.Webhooks, 2)
+	assert.Equal(t, res.Attempts, 2)
+	assert.Equal(t, res.Failures, 1)
+	assert.Equal(t, res.FailureRate, 0.5)
+	assert.Equal(t, res.MaxLatency, time.Second)
+	assert.Equal(t, res.AverageLatency, 600*time.Millisecond)
+	assert.Equal(t, res.StatusCodes, map[int]int{200: 1, 500: 1})
+	assert.Equal(t, res.LastSuccess, time.Date(2015, 10, 26, 12, 0, 0, 0, time.UTC))
+}
+
+func TestWebhookSubscriptionUnpauseWhenHealthy(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhook-subscription.json"))
+
+	checks := 0
+	check := func(ctx context.Context) error {
+		checks++
+		if checks < 2 {
+			return errors.New("unhealthy")
+		}
+		return nil
+	}
+
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589"}}}, Paused: true}
+	err := subscription.UnpauseWhenHealthy(ctx, check, time.Millisecond)
+
+	assert.Nil(t, err)
+	assert.Equal(t, checks, 2)
+	assert.False(t, subscription.Paused)
+
+	err = subscription.UnpauseWhenHealthy(ctx, check, time.Millisecond)
+
+	assert.Nil(t, err)
+	assert.Equal(t, checks, 2)
+}
+
+func TestWebhookSubscriptionUnpauseWhenHealthyDefaultInterval(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhook-subscription.json"))
+	subscription := &WebhookSubscription{Resource: Resource{client: c, Links: Links{"self": Link{Href: "https://api-sandbox.dwolla.com/webhook-subscriptions/077dfffb-4852-412f-96b6-0fe668066589"}}}, Paused: true}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err := subscription.UnpauseWhenHealthy(cancelled, func(context.Context) error { return errors.New("unhealthy") }, 0)
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Contains(t, err.Error(), "unhealthy")
+	assert.True(t, subscription.Paused)
+
+	assert.Error(t, subscription.UnpauseWhenHealthy(ctx, nil, 0))
+	assert.True(t, subscription.Paused)
+
+	err = subscription.UnpauseWhenHealthy(ctx, func(context.Context) error { return nil }, 0)
+
+	assert.Nil(t, err)
+	assert.False(t, subscription.Paused)
+}
+
+func TestClientEnsureWebhookSubscriptions(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /webhook-subscriptions":                                         {200, filepath.Join("testdata", "webhook-subscriptions.json")},
+		"POST /webhook-subscriptions":                                        {201, filepath.Join("testdata", "webhook-subscription.json")},
+		"DELETE /webhook-subscriptions/f4d21628-fde2-4d3a-b69a-0a7cb42adc4c": {200, filepath.Join("testdata", "webhook-subscription.json")},
+	})
+
+	desired := []WebhookSubscriptionSpec{{URL: "http://myapplication.com/webhooks", Secret: "secret"}}
+
+	plan, err := c.EnsureWebhookSubscriptions(ctx, desired, true)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Changes, 2)
+	assert.Equal(t, plan.Changes[0].Action, WebhookSubscriptionActionRemove)
+	assert.Equal(t, plan.Changes[0].ID, "f4d21628-fde2-4d3a-b69a-0a7cb42adc4c")
+	assert.Equal(t, plan.Changes[1].Action, WebhookSubscriptionActionCreate)
+	assert.False(t, plan.Changes[1].Applied)
+	assert.Contains(t, plan.String(), "remove  https://destination.url")
+	assert.Len(t, mc.requestLog(), 1)
+
+	plan, err = c.EnsureWebhookSubscriptions(ctx, desired, false)
+
+	assert.Nil(t, err)
+	assert.True(t, plan.Changes[0].Applied)
+	assert.True(t, plan.Changes[1].Applied)
+	assert.Equal(t, plan.Changes[1].ID, "077dfffb-4852-412f-96b6-0fe668066589")
+	assert.Len(t, mc.requestLog(), 4)
+}
+
+func TestClientEnsureWebhookSubscriptionsUnchanged(t *testing.T) {
+	c := newMockClient(200, filepath.Join("testdata", "webhook-subscriptions.json"))
+
+	plan, err := c.EnsureWebhookSubscriptions(ctx, []WebhookSubscriptionSpec{{URL: "https://destination.url"}}, false)
+
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+	assert.Equal(t, plan.String(), "No webhook subscription changes\n")
+}
+
+func TestClientEnsureWebhookSubscriptionsError(t *testing.T) {
+	c := newMockClient(404, filepath.Join("testdata", "resource-not-found.json"))
+
+	_, err := c.EnsureWebhookSubscriptions(ctx, []WebhookSubscriptionSpec{{URL: "https://destination.url"}, {URL: "https://destination.url"}}, true)
+	assert.Error(t, err)
+
+	_, err = c.EnsureWebhookSubscriptions(ctx, []WebhookSubscriptionSpec{{}}, true)
+	assert.Error(t, err)
+
+	_, err = c.EnsureWebhookSubscriptions(ctx, []WebhookSubscriptionSpec{{URL: "https://destination.url"}}, true)
+	assert.Error(t, err)
+}
+
+func TestClientEnsureWebhookSubscriptionsRotate(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /webhook-subscriptions":                                         {200, filepath.Join("testdata", "webhook-subscriptions.json")},
+		"POST /webhook-subscriptions":                                        {201, filepath.Join("testdata", "webhook-subscription.json")},
+		"DELETE /webhook-subscriptions/f4d21628-fde2-4d3a-b69a-0a7cb42adc4c": {200, filepath.Join("testdata", "webhook-subscription.json")},
+	})
+
+	desired := []WebhookSubscriptionSpec{{URL: "https://destination.url", Secret: "new", RotateSecret: true}}
+
+	plan, err := c.EnsureWebhookSubscriptions(ctx, desired, false)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Changes, 1)
+	assert.Equal(t, plan.Changes[0].Action, WebhookSubscriptionActionRotate)
+	assert.True(t, plan.Changes[0].Applied)
+	assert.Equal(t, plan.Changes[0].ID, "077dfffb-4852-412f-96b6-0fe668066589")
+	assert.Equal(t, plan.Changes[0].PreviousID, "f4d21628-fde2-4d3a-b69a-0a7cb42adc4c")
+	assert.True(t, desired[0].RotateSecret)
+	assert.Len(t, mc.requestLog(), 3)
+	assert.Equal(t, mc.requestLog()[1].Method, "POST")
+	assert.Equal(t, mc.requestLog()[2].Method, "DELETE")
+
+	desired[0].RotateSecret = false
+
+	plan, err = c.EnsureWebhookSubscriptions(ctx, desired, false)
+
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+}
+
+func TestClientEnsureWebhookSubscriptionsRotateError(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /webhook-subscriptions":  {200, filepath.Join("testdata", "webhook-subscriptions.json")},
+		"POST /webhook-subscriptions": {400, filepath.Join("testdata", "validation-error.json")},
+	})
+
+	desired := []WebhookSubscriptionSpec{{URL: "https://destination.url", Secret: "new", RotateSecret: true}}
+
+	plan, err := c.EnsureWebhookSubscriptions(ctx, desired, false)
+
+	assert.Error(t, err)
+	assert.False(t, plan.Changes[0].Applied)
+	assert.True(t, desired[0].RotateSecret)
+	assert.Equal(t, 0, countMockRequests(mc, "DELETE", "/webhook-subscriptions/f4d21628-fde2-4d3a-b69a-0a7cb42adc4c"))
+}
+
+func TestClientEnsureWebhookSubscriptionsPause(t *testing.T) {
+	c, mc := newMockRoutedClient(map[string]mockRoute{
+		"GET /webhook-subscriptions":                                       {200, filepath.Join("testdata", "webhook-subscriptions.json")},
+		"POST /webhook-subscriptions/f4d21628-fde2-4d3a-b69a-0a7cb42adc4c": {200, filepath.Join("testdata", "webhook-subscription.json")},
+	})
+
+	plan, err := c.EnsureWebhookSubscriptions(ctx, []WebhookSubscriptionSpec{{URL: "https://destination.url", Paused: true}}, false)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Changes, 1)
+	assert.Equal(t, plan.Changes[0].Action, WebhookSubscriptionActionPause)
+	assert.True(t, plan.Changes[0].Applied)
+	assert.Equal(t, 1, countMockRequests(mc, "POST", "/webhook-subscriptions/f4d21628-fde2-4d3a-b69a-0a7cb42adc4c"))
+}